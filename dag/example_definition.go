@@ -0,0 +1,35 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/definition"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
+)
+
+var (
+	path_definition_example = "./dags/example"
+)
+
+// golang func ที่ถูกอ้างอิงจาก dags/example/example_definition.yaml
+func registerDefinitionExample() {
+	definition.RegisterGolangFunc("example_print_trigger_config", func(ctx context.Context) (interface{}, error) {
+		val := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY)
+		jobRunner := val.(scheduler.JobRunner)
+
+		fmt.Println("trigger config", constants.PARSE_SYNC_MAP_TO_MAP(jobRunner.GetTriggerConfig()))
+		return nil, nil
+	})
+	definition.RegisterGolangFunc("example_consider_branch", func(ctx context.Context) (interface{}, error) {
+		return "branch1", nil
+	})
+	definition.RegisterCallback("example_print_exception", func(ctx context.Context) error {
+		val := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY)
+		jobRunner := val.(scheduler.JobRunner)
+
+		fmt.Println("task", jobRunner.GetTask().GetName(), "with exception", jobRunner.GetException().Error())
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func startDagExampleHttpExecutor() {
+	config := scheduler.NewDefaultSchedulerConfig()
+	schedulerInstance := scheduler.NewScheduler("", "example_http_executor", "ทดสอบ http_executor", config)
+
+	job := scheduler.NewJob(nil)
+	job.AddTask(
+		task.NewTask("healthcheck", executor.NewHttpExecutor(executor.HttpExecutorConfig{
+			Method:              http.MethodGet,
+			Url:                 fmt.Sprintf("http://localhost:%s/healthcheck", constants.ENV_APP_PORT),
+			ExpectedStatusCodes: []int{http.StatusOK},
+			Timeout:             time.Second * 10,
+		})),
+		task.NewTask("print_response", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+			val := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY)
+			jobRunner := val.(scheduler.JobRunner)
+
+			resp, _ := jobRunner.GetTaskValue("healthcheck")
+			fmt.Println("healthcheck response", resp)
+			return nil, nil
+		})),
+	)
+
+	schedulerInstance.RegisterJob(job)
+	register(schedulerInstance)
+}
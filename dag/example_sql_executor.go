@@ -0,0 +1,27 @@
+package dag
+
+import (
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func startDagExampleSqlExecutor() {
+	config := scheduler.NewDefaultSchedulerConfig()
+	schedulerInstance := scheduler.NewScheduler("", "example_sql_executor", "ทดสอบ sql_executor", config)
+
+	job := scheduler.NewJob(map[string]interface{}{"scheduler_name": "example_golang_executor"})
+	job.AddTask(
+		task.NewTask("count_job", executor.NewSqlExecutor(executor.SqlExecutorConfig{
+			Query:       `SELECT COUNT(*) AS total FROM jobs WHERE scheduler_name = :args.scheduler_name`,
+			FetchResult: true,
+		})),
+		task.NewTask("count_job_success", executor.NewSqlExecutor(executor.SqlExecutorConfig{
+			Query:       `SELECT COUNT(*) AS total FROM jobs WHERE scheduler_name = :args.scheduler_name AND status = 'SUCCESS'`,
+			FetchResult: true,
+		})),
+	)
+
+	schedulerInstance.RegisterJob(job)
+	register(schedulerInstance)
+}
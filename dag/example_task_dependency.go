@@ -0,0 +1,45 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+// dag pipeline
+// extract >> [transform1, transform2] >> load
+// transform1 และ transform2 จะทำงานพร้อมกัน
+func startDagExampleTaskDependency() {
+	config := scheduler.NewDefaultSchedulerConfig()
+	schedulerInstance := scheduler.NewScheduler("*/5 * * * *", "example_task_dependency", "ทดสอบ task dependency", config)
+
+	extract := task.NewTask("extract", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		fmt.Println("extract")
+		return nil, nil
+	}))
+	transform1 := task.NewTask("transform1", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		fmt.Println("transform1")
+		return nil, nil
+	}))
+	transform2 := task.NewTask("transform2", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		fmt.Println("transform2")
+		return nil, nil
+	}))
+	load := task.NewTask("load", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		fmt.Println("load")
+		return nil, nil
+	}))
+
+	job := scheduler.NewJob(nil)
+	job.AddTask(extract, transform1, transform2, load)
+	job.SetDownstream(extract, transform1, transform2)
+	job.SetUpstream(load, transform1, transform2)
+
+	if err := schedulerInstance.RegisterJob(job); err != nil {
+		panic(err)
+	}
+	register(schedulerInstance)
+}
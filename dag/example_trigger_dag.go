@@ -0,0 +1,49 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+/* example_trigger_dag trigger example_triggered_dag and wait until it was finished */
+func startDagExampleTriggerDag() {
+	triggeredInstance := scheduler.NewScheduler("", "example_triggered_dag", "ทดสอบ dag ที่ถูก trigger จาก dag อื่น", scheduler.NewDefaultSchedulerConfig())
+	triggeredJob := scheduler.NewJob(nil)
+	triggeredJob.AddTask(
+		task.NewTask("print_config", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+			jobRunner := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(scheduler.JobRunner)
+			fmt.Println("triggered by", constants.PARSE_SYNC_MAP_TO_MAP(jobRunner.GetTriggerConfig()))
+			return nil, nil
+		})),
+	)
+	triggeredInstance.RegisterJob(triggeredJob)
+	register(triggeredInstance)
+
+	config := scheduler.NewDefaultSchedulerConfig()
+	config.TaskTimeout = time.Minute * 5
+	schedulerInstance := scheduler.NewScheduler("", "example_trigger_dag", "ทดสอบ trigger dag อื่นและรอ dag อื่นทำงานสำเร็จ", config)
+	job := scheduler.NewJob(nil)
+	job.AddTask(
+		task.NewTask("trigger", executor.NewTriggerDagExecutor(executor.TriggerDagExecutorConfig{
+			SchedulerName: "example_triggered_dag",
+			Config: map[string]interface{}{
+				"source": "example_trigger_dag",
+			},
+			WaitForFinish: true,
+			PokeInterval:  time.Second,
+		})),
+		task.NewTask("sensor", executor.NewExternalDagSensor(executor.ExternalDagSensorConfig{
+			SchedulerName: "example_triggered_dag",
+			PokeInterval:  time.Second,
+			FailOnFailed:  true,
+		})),
+	)
+	schedulerInstance.RegisterJob(job)
+	register(schedulerInstance)
+}
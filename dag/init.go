@@ -2,51 +2,112 @@ package dag
 
 import (
 	"context"
-	"fmt"
+	"sync"
 
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
 )
 
+const (
+	leader_lease_name = "scheduler"
+)
+
 var (
-	SCHEDULERS = make([]*scheduler.SchedulerInstance, 0)
+	SCHEDULERS = scheduler.NewRegistry()
+	ELECTOR    = newLeaderElector()
 )
 
+/* nil when leader election is disabled, every replica fire cronjob */
+func newLeaderElector() *scheduler.LeaderElector {
+	if !constants.ENV_LEADER_ELECTION {
+		return nil
+	}
+	return scheduler.NewLeaderElector(leader_lease_name, constants.ENV_NODE_ID, constants.ENV_LEADER_LEASE_TTL)
+}
+
 func call() {
+	definitionPaths = []string{constants.ENV_DAG_DEFINITION_PATH}
 	if constants.ENV_ENABLED_DAG_EXAMPLE {
+		registerDefinitionExample()
+		definitionPaths = append(definitionPaths, path_definition_example)
 		startDagExampleGolang()
 		startDagExampleTaskBash()
 		startDagExampleWorkWithoutCronjob()
 		startDagExampleTaskBranch()
+		startDagExampleTaskDependency()
+		startDagExampleHttpExecutor()
+		startDagExampleSqlExecutor()
+		startDagExampleTriggerDag()
 	}
 	//startdagExampleNewbie()
+
+	printReloadResult(reloadDefinition())
 }
 
 func register(scheduler *scheduler.SchedulerInstance) {
-	SCHEDULERS = append(SCHEDULERS, scheduler)
+	if err := SCHEDULERS.Add(scheduler); err != nil {
+		panic(err)
+	}
 }
 
 func StartAllDag(stop chan bool, adapterConnection connection.DatabaseAdapterConnection) {
+	if ELECTOR != nil {
+		SCHEDULERS.SetElector(ELECTOR)
+		ELECTOR.Start(adapterConnection)
+		defer ELECTOR.Stop()
+	}
+
+	/* running job of previous process is detected by time of node started */
+	detector := scheduler.NewOrphanDetector(constants.ENV_NODE_ID, SCHEDULERS, constants.ENV_JOB_HEARTBEAT_TIMEOUT)
+
+	reloadMutex.Lock()
 	call()
-	for _, scheduler := range SCHEDULERS {
-		scheduler.SetAdapter(adapterConnection)
-		if err := scheduler.Start(); err != nil {
-			panic(fmt.Sprintf("failed to start scheduler %s: %s", scheduler.GetName(), err.Error()))
-		}
-		defer scheduler.Stop()
-
-		triggers, err := adapterConnection.GetRepository().GetTriggerTimer(context.Background(), scheduler.GetName())
-		if err != nil {
-			panic(err)
-		}
-		if len(triggers) > 0 {
-			for _, trigger := range triggers {
-				scheduler.Run(trigger)
-				fmt.Println("load scheduler timer with job", trigger.JobId)
-			}
-		}
+	if err := SCHEDULERS.Start(adapterConnection); err != nil {
+		panic(err)
 	}
+	reloadMutex.Unlock()
+
+	detector.Start(adapterConnection)
+	defer detector.Stop()
+
+	/* future trigger of every scheduler on node is run by dispatcher, claim is kept alive like heartbeat of job */
+	dispatcher := scheduler.NewDispatcher(constants.ENV_NODE_ID, SCHEDULERS, constants.ENV_TRIGGER_POLL_INTERVAL, constants.ENV_TRIGGER_BATCH_SIZE, constants.ENV_JOB_HEARTBEAT_TIMEOUT)
+	dispatcher.Start(adapterConnection)
+
+	/* worker call task of scheduler on queue mode which was enqueued by any node */
+	var worker *scheduler.Worker
+	if constants.ENV_WORKER_CONCURRENCY > 0 {
+		worker = scheduler.NewWorker(constants.ENV_NODE_ID, SCHEDULERS, constants.ENV_WORKER_CONCURRENCY, constants.ENV_WORKER_POLL_INTERVAL, constants.ENV_WORKER_HEARTBEAT_TIMEOUT)
+		worker.Start(adapterConnection)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go watchDefinition(done, definitionPaths...)
 
 	<-stop
+	dispatcher.Stop()
+	shutdown(worker)
+}
+
+/* scheduler and worker share grace period, heartbeat of detector and leader lease are kept until both was finished */
+func shutdown(worker *scheduler.Worker) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ENV_SHUTDOWN_GRACE_PERIOD)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		SCHEDULERS.Shutdown(ctx)
+	}()
+	if worker != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
 }
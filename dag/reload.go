@@ -0,0 +1,180 @@
+package dag
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/definition"
+	"github.com/fsnotify/fsnotify"
+)
+
+var (
+	reloadMutex              = new(sync.Mutex)
+	duration_reload_debounce = 1 * time.Second
+	definitionPaths          = make([]string, 0)
+	definitionFiles          = map[string]definition.File{} // key is scheduler name, only scheduler which was loaded by definition file
+)
+
+type ReloadResult struct {
+	Added    []string `json:"added"`
+	Replaced []string `json:"replaced"`
+	Removed  []string `json:"removed"`
+	Errors   []string `json:"errors"`
+}
+
+/*
+reload every dag definition file, scheduler which was registered by golang can not be replaced by definition file.
+definition of invalid file is kept until file was fixed
+*/
+func ReloadDefinition() ReloadResult {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	return reloadDefinition()
+}
+
+func reloadDefinition() ReloadResult {
+	var result = ReloadResult{
+		Added:    make([]string, 0),
+		Replaced: make([]string, 0),
+		Removed:  make([]string, 0),
+		Errors:   make([]string, 0),
+	}
+	var loaded = map[string]definition.File{}
+	var rejected = map[string]bool{} // path of file or directory which can not be loaded
+
+	/* every scheduler is kept when schema can not be loaded */
+	loader, err := definition.NewLoader()
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	for _, path := range definitionPaths {
+		files, err := loader.LoadDirectory(path)
+		if err != nil {
+			if errs, ok := err.(definition.ValidationErrors); ok {
+				for _, item := range errs {
+					if validationErr, ok := item.(definition.ValidationError); ok {
+						rejected[validationErr.File] = true
+					}
+					result.Errors = append(result.Errors, item.Error())
+				}
+			} else {
+				rejected[filepath.Clean(path)] = true
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+
+		for _, file := range files {
+			name := file.Scheduler.GetName()
+			if other, ok := loaded[name]; ok {
+				rejected[file.Path] = true
+				result.Errors = append(result.Errors, fmt.Sprintf("dag definition %s was rejected: scheduler %s was defined in %s", file.Path, name, other.Path))
+				continue
+			}
+
+			previous, isDefinition := definitionFiles[name]
+			switch {
+			case isDefinition && previous.Path == file.Path && previous.Checksum == file.Checksum:
+				/* file was not changed */
+				loaded[name] = previous
+			case isDefinition:
+				if err := SCHEDULERS.Replace(file.Scheduler); err != nil {
+					loaded[name] = previous
+					result.Errors = append(result.Errors, fmt.Sprintf("dag definition %s was rejected: %s", file.Path, err.Error()))
+					continue
+				}
+				loaded[name] = file
+				result.Replaced = append(result.Replaced, name)
+			case SCHEDULERS.Get(name) != nil:
+				result.Errors = append(result.Errors, fmt.Sprintf("dag definition %s was rejected: scheduler name was registered", file.Path))
+			default:
+				if err := SCHEDULERS.Add(file.Scheduler); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("dag definition %s was rejected: %s", file.Path, err.Error()))
+					continue
+				}
+				loaded[name] = file
+				result.Added = append(result.Added, name)
+			}
+		}
+	}
+
+	for name, previous := range definitionFiles {
+		if _, ok := loaded[name]; ok {
+			continue
+		}
+		if rejected[previous.Path] || rejected[filepath.Dir(previous.Path)] {
+			loaded[name] = previous
+			continue
+		}
+		if err := SCHEDULERS.Remove(name); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	sort.Strings(result.Removed)
+
+	definitionFiles = loaded
+	return result
+}
+
+func printReloadResult(result ReloadResult) {
+	for _, err := range result.Errors {
+		fmt.Println(err)
+	}
+	for _, name := range result.Added {
+		fmt.Println("load dag definition", name)
+	}
+	for _, name := range result.Replaced {
+		fmt.Println("reload dag definition", name)
+	}
+	for _, name := range result.Removed {
+		fmt.Println("remove dag definition", name)
+	}
+}
+
+/* reload dag definition when any file in directory was changed */
+func watchDefinition(done <-chan struct{}, paths ...string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Println("failed to watch dag definition:", err.Error())
+		return
+	}
+	defer watcher.Close()
+
+	for _, path := range paths {
+		if err := watcher.Add(path); err != nil {
+			fmt.Println(fmt.Sprintf("failed to watch dag definition path %s: %s", path, err.Error()))
+		}
+	}
+
+	/* editor may write file more than once, reload when file was not changed within debounce */
+	debounce := time.NewTimer(duration_reload_debounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			debounce.Reset(duration_reload_debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Println("dag definition watcher:", err.Error())
+		case <-debounce.C:
+			printReloadResult(ReloadDefinition())
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/BlackMocca/sqlx"
+	pg "github.com/lib/pq"
+)
+
+const (
+	SQL_CONNECTION_DEFAULT = "default"
+
+	sql_connection_env_prefix = "SQL_CONNECTION_"
+)
+
+var (
+	sqlConnections = new(sync.Map)
+)
+
+/* connection for sql executor, driver is considered from scheme of uri */
+func NewSqlConnection(ctx context.Context, uri string) (*sqlx.DB, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var driverName = u.Scheme
+	var addr = uri
+	switch driverName {
+	case "postgres", "postgresql":
+		driverName = postgres_driver
+		addr, err = pg.ParseURL(uri)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sqlx.ConnectContext(ctx, driverName, addr)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func RegisterSqlConnection(name string, db *sqlx.DB) {
+	sqlConnections.Store(name, db)
+}
+
+func GetSqlConnection(name string) (*sqlx.DB, error) {
+	if name == "" {
+		name = SQL_CONNECTION_DEFAULT
+	}
+	val, ok := sqlConnections.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("sql connection %s was not registered", name)
+	}
+	return val.(*sqlx.DB), nil
+}
+
+/*
+register sql connection from environment SQL_CONNECTION_<NAME>=<uri>
+name of connection is lower case of <NAME>
+*/
+func RegisterSqlConnectionFromEnv(ctx context.Context) error {
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, sql_connection_env_prefix) {
+			continue
+		}
+		pair := strings.SplitN(strings.TrimPrefix(env, sql_connection_env_prefix), "=", 2)
+		if len(pair) != 2 || pair[1] == "" {
+			continue
+		}
+		db, err := NewSqlConnection(ctx, pair[1])
+		if err != nil {
+			return fmt.Errorf("failed to connect sql connection %s: %s", strings.ToLower(pair[0]), err.Error())
+		}
+		RegisterSqlConnection(strings.ToLower(pair[0]), db)
+	}
+	return nil
+}
+
+/* default connection is closed by database adapter */
+func CloseSqlConnections() {
+	sqlConnections.Range(func(key, value any) bool {
+		if key.(string) == SQL_CONNECTION_DEFAULT {
+			return true
+		}
+		value.(*sqlx.DB).Close()
+		return true
+	})
+}
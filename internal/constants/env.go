@@ -14,6 +14,14 @@ func getEnv(key string, defaultValue string) string {
 	return val
 }
 
+func getHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "localhost"
+	}
+	return hostname
+}
+
 var (
 	ENV_APP_PORT                     = getEnv("APP_PORT", "3000")
 	ENV_API_AUTH_ADAPTER             = getEnv("API_AUTH_ADAPTER", "basicauth")
@@ -25,4 +33,15 @@ var (
 	ENV_DATABASE_ADAPTER             = getEnv("DATABASE_ADAPTER", "postgres")
 	ENV_DATABASE_URL                 = getEnv("DATABASE_URL", "")
 	ENV_ENABLED_DAG_EXAMPLE          = cast.ToBool(getEnv("ENABLED_DAG_EXAMPLE", "true"))
+	ENV_DAG_DEFINITION_PATH          = getEnv("DAG_DEFINITION_PATH", "./dags")
+	ENV_NODE_ID                      = getEnv("NODE_ID", getHostname())
+	ENV_WORKER_CONCURRENCY           = cast.ToInt(getEnv("WORKER_CONCURRENCY", "4"))
+	ENV_WORKER_POLL_INTERVAL         = cast.ToDuration(getEnv("WORKER_POLL_INTERVAL", "1s"))
+	ENV_WORKER_HEARTBEAT_TIMEOUT     = cast.ToDuration(getEnv("WORKER_HEARTBEAT_TIMEOUT", "30s"))
+	ENV_LEADER_ELECTION              = cast.ToBool(getEnv("LEADER_ELECTION", "false"))
+	ENV_LEADER_LEASE_TTL             = cast.ToDuration(getEnv("LEADER_LEASE_TTL", "15s"))
+	ENV_JOB_HEARTBEAT_TIMEOUT        = cast.ToDuration(getEnv("JOB_HEARTBEAT_TIMEOUT", "60s"))
+	ENV_SHUTDOWN_GRACE_PERIOD        = cast.ToDuration(getEnv("SHUTDOWN_GRACE_PERIOD", "30s"))
+	ENV_TRIGGER_POLL_INTERVAL        = cast.ToDuration(getEnv("TRIGGER_POLL_INTERVAL", "1s"))
+	ENV_TRIGGER_BATCH_SIZE           = cast.ToInt(getEnv("TRIGGER_BATCH_SIZE", "100"))
 )
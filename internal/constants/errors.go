@@ -2,4 +2,6 @@ package constants
 
 var (
 	ERROR_ALREADY_EXISTS = "already exists"
+	ERROR_LEASE_LOST     = "leader lease was lost"
+	ERROR_JOB_CLAIMED    = "job was claimed by another rerun"
 )
@@ -19,10 +19,38 @@ const (
 	JOB_STATUS_RUNNING JobStatus = "RUNNING"
 	JOB_STATUS_SUCCESS JobStatus = "SUCCESS"
 	JOB_STATUS_FAILED  JobStatus = "FAILED"
+
+	JOB_STATUS_UP_FOR_RETRY      JobStatus = "UP_FOR_RETRY"      // task was failed and waiting for retry delay
+	JOB_STATUS_UP_FOR_RESCHEDULE JobStatus = "UP_FOR_RESCHEDULE" // sensor was not met and release slot until next poke
+	JOB_STATUS_TIMEOUT           JobStatus = "TIMEOUT"           // job or task was cancelled by deadline
+	JOB_STATUS_SKIPPED           JobStatus = "SKIPPED"           // task was not run by trigger rule
+)
+
+type RerunMode string
+
+const (
+	RERUN_MODE_FULL   RerunMode = "full"   // run every task again
+	RERUN_MODE_RESUME RerunMode = "resume" // run from task which was not success on previous run
+)
+
+type OrphanPolicy string
+
+/* running job which node stopped heartbeat */
+const (
+	ORPHAN_POLICY_FAIL    OrphanPolicy = "fail"    // job and running task are failed with exception orphaned (default)
+	ORPHAN_POLICY_REQUEUE OrphanPolicy = "requeue" // job is run again by resume mode on node which detect it
+)
+
+const (
+	ORPHAN_EXCEPTION = "orphaned"
 )
 
 type JobContextKey string
 
 const (
-	JOB_RUNNER_INSTANCE_KEY JobContextKey = "instance" // using load current status of job runner
+	JOB_RUNNER_INSTANCE_KEY JobContextKey = "instance"       // using load current status of job runner
+	MAP_INDEX_KEY           JobContextKey = "map_index"      // index of item on instance of mapped task
+	MAP_ITEM_KEY            JobContextKey = "map_item"       // item of upstream value on instance of mapped task
+	TASK_KEY                JobContextKey = "task"           // task which is called or call callback of task
+	TASK_EXCEPTION_KEY      JobContextKey = "task_exception" // error of task which call OnTaskFailure or OnTaskRetry
 )
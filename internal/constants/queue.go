@@ -0,0 +1,19 @@
+package constants
+
+type ExecutionMode string
+
+/* where task of scheduler is called */
+const (
+	EXECUTION_MODE_LOCAL ExecutionMode = "local" // task is called on node which run job (default)
+	EXECUTION_MODE_QUEUE ExecutionMode = "queue" // task is enqueued in database and called by worker of any node
+)
+
+type QueueStatus string
+
+const (
+	QUEUE_STATUS_QUEUED    QueueStatus = "QUEUED"  // waiting for worker
+	QUEUE_STATUS_RUNNING   QueueStatus = "RUNNING" // claimed by worker which keep heartbeat
+	QUEUE_STATUS_SUCCESS   QueueStatus = "SUCCESS"
+	QUEUE_STATUS_FAILED    QueueStatus = "FAILED"
+	QUEUE_STATUS_CANCELLED QueueStatus = "CANCELLED" // job node stop waiting, such as task timeout
+)
@@ -3,6 +3,43 @@ package constants
 type TaskType string
 
 const (
-	TASK_TYPE_BASE_TASK   TaskType = "BASE_TASK"
-	TASK_TYPE_BRANCH_TASK TaskType = "BRANCH_TASK"
+	TASK_TYPE_BASE_TASK          TaskType = "BASE_TASK"
+	TASK_TYPE_BRANCH_TASK        TaskType = "BRANCH_TASK"
+	TASK_TYPE_SENSOR_TASK        TaskType = "SENSOR_TASK"
+	TASK_TYPE_MAPPED_TASK        TaskType = "MAPPED_TASK"
+	TASK_TYPE_SHORT_CIRCUIT_TASK TaskType = "SHORT_CIRCUIT_TASK"
+)
+
+type SensorMode string
+
+const (
+	SENSOR_MODE_POKE       SensorMode = "poke"       // hold slot of scheduler until condition was met
+	SENSOR_MODE_RESCHEDULE SensorMode = "reschedule" // release slot of scheduler between poke
+)
+
+type BranchJoinMode string
+
+/* join of pipelines which were selected by branch task */
+const (
+	BRANCH_JOIN_MODE_ALL           BranchJoinMode = "all"           // wait for every pipeline, branch is failed when any pipeline was failed (default)
+	BRANCH_JOIN_MODE_FIRST_SUCCESS BranchJoinMode = "first_success" // continue when first pipeline was success, pending task of another pipeline is skipped
+)
+
+type RetryBackoff string
+
+/* delay between retry of task */
+const (
+	RETRY_BACKOFF_FIXED       RetryBackoff = "fixed"       // every retry wait for retry delay (default)
+	RETRY_BACKOFF_EXPONENTIAL RetryBackoff = "exponential" // retry delay is doubled on each retry
+	RETRY_BACKOFF_JITTER      RetryBackoff = "jitter"      // random delay between 0 and exponential delay
+)
+
+type TriggerRule string
+
+/* rule of task which consider from status of upstream tasks */
+const (
+	TRIGGER_RULE_ALL_SUCCESS TriggerRule = "all_success" // all upstreams were success (default)
+	TRIGGER_RULE_ALL_DONE    TriggerRule = "all_done"    // all upstreams were finished whatever status
+	TRIGGER_RULE_ONE_FAILED  TriggerRule = "one_failed"  // at least one upstream was failed
+	TRIGGER_RULE_NONE_FAILED TriggerRule = "none_failed" // all upstreams were success or skipped
 )
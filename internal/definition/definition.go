@@ -0,0 +1,136 @@
+package definition
+
+/* format of dag definition file (yaml or json) */
+type DagDefinition struct {
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	CronjobExpression string                 `json:"cronjob_expression"`
+	Config            ConfigDefinition       `json:"config"`
+	Arguments         map[string]interface{} `json:"arguments"`
+	Tasks             []TaskDefinition       `json:"tasks"`
+	TaskGroups        []TaskGroupDefinition  `json:"task_groups"`
+}
+
+/* group of task which is embedded by task type group, name of task is prefixed by name of embedded task */
+type TaskGroupDefinition struct {
+	Name  string           `json:"name"`
+	Tasks []TaskDefinition `json:"tasks"`
+}
+
+type ConfigDefinition struct {
+	MaxActiveConcurrent int    `json:"max_active_concurrent"`
+	RetryTimes          int    `json:"retry_times"`
+	RetryDelay          string `json:"retry_delay"`
+	JobTimeout          string `json:"job_timeout"`
+	TaskTimeout         string `json:"task_timeout"`
+	JobMode             string `json:"job_mode"`
+	CatchUp             bool   `json:"catch_up"`
+	ExecutionMode       string `json:"execution_mode"`
+	OrphanPolicy        string `json:"orphan_policy"`
+	MaxValueSize        *int   `json:"max_value_size"` // 0 is no limit, default is limit of scheduler config
+	OnSuccess           string `json:"on_success"`     // name of registered callback
+	OnError             string `json:"on_error"`       // name of registered callback
+}
+
+type TaskDefinition struct {
+	Name        string                       `json:"name"`
+	Type        string                       `json:"type"`
+	DependsOn   []string                     `json:"depends_on"`
+	TriggerRule string                       `json:"trigger_rule"`
+	Bash        *BashDefinition              `json:"bash"`
+	Http        *HttpDefinition              `json:"http"`
+	Sql         *SqlDefinition               `json:"sql"`
+	Golang      *GolangDefinition            `json:"golang"`
+	Branch      *BranchDefinition            `json:"branch"`
+	TriggerDag  *TriggerDagDefinition        `json:"trigger_dag"`
+	DagSensor   *ExternalDagSensorDefinition `json:"external_dag_sensor"`
+	Sensor      *SensorDefinition            `json:"sensor"`
+	Mapped      *MappedDefinition            `json:"mapped"`
+	Group       string                       `json:"group"` // name of task group
+	Circuit     *ShortCircuitDefinition      `json:"short_circuit"`
+	Options     *TaskOptionsDefinition       `json:"options"`
+}
+
+/* option of task which override config, callback is name of registered callback */
+type TaskOptionsDefinition struct {
+	RetryTimes    *int    `json:"retry_times"`
+	RetryDelay    *string `json:"retry_delay"`
+	RetryBackoff  string  `json:"retry_backoff"`
+	MaxRetryDelay string  `json:"max_retry_delay"`
+	Timeout       *string `json:"timeout"`
+	SLA           string  `json:"sla"`
+	OnSuccess     string  `json:"on_success"`
+	OnFailure     string  `json:"on_failure"`
+	OnRetry       string  `json:"on_retry"`
+	OnSLAMiss     string  `json:"on_sla_miss"`
+}
+
+type BashDefinition struct {
+	Cmd        string `json:"cmd"`
+	ShowResult bool   `json:"show_result"`
+}
+
+type HttpDefinition struct {
+	Method              string            `json:"method"`
+	Url                 string            `json:"url"`
+	Headers             map[string]string `json:"headers"`
+	Body                string            `json:"body"`
+	ExpectedStatusCodes []int             `json:"expected_status_codes"`
+	Timeout             string            `json:"timeout"`
+}
+
+type SqlDefinition struct {
+	Connection  string `json:"connection"`
+	Query       string `json:"query"`
+	FetchResult bool   `json:"fetch_result"`
+}
+
+type GolangDefinition struct {
+	Func string `json:"func"` // name of registered golang func
+}
+
+type BranchDefinition struct {
+	Func     string                      `json:"func"` // name of registered golang func which return branch name or list of branch name
+	Branches map[string][]TaskDefinition `json:"branches"`
+	JoinMode string                      `json:"join_mode"` // all or first_success, default is all
+}
+
+type TriggerDagDefinition struct {
+	SchedulerName string                 `json:"scheduler_name"`
+	Config        map[string]interface{} `json:"config"`
+	WaitForFinish bool                   `json:"wait_for_finish"`
+	PokeInterval  string                 `json:"poke_interval"`
+}
+
+type ExternalDagSensorDefinition struct {
+	SchedulerName  string `json:"scheduler_name"`
+	ExecutionDelta string `json:"execution_delta"`
+	PokeInterval   string `json:"poke_interval"`
+	FailOnFailed   bool   `json:"fail_on_failed"`
+}
+
+/* sensor must have one condition of file, sql, http or external_dag */
+type SensorDefinition struct {
+	Mode         string                       `json:"mode"`
+	PokeInterval string                       `json:"poke_interval"`
+	Timeout      string                       `json:"timeout"`
+	File         *FileSensorDefinition        `json:"file"`
+	Sql          *SqlDefinition               `json:"sql"`
+	Http         *HttpDefinition              `json:"http"`
+	ExternalDag  *ExternalDagSensorDefinition `json:"external_dag"`
+}
+
+type FileSensorDefinition struct {
+	Pattern string `json:"pattern"`
+}
+
+type ShortCircuitDefinition struct {
+	Func string `json:"func"` // name of registered golang func which return bool
+}
+
+/* executor of mapped task is defined on field of type, such as type http is defined on field http of task */
+type MappedDefinition struct {
+	Type      string `json:"type"`
+	Upstream  string `json:"upstream"`
+	MaxActive int    `json:"max_active"`
+}
@@ -0,0 +1,622 @@
+package definition
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cast"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	task_type_bash   = "bash"
+	task_type_http   = "http"
+	task_type_sql    = "sql"
+	task_type_golang = "golang"
+	task_type_branch = "branch"
+
+	task_type_trigger_dag         = "trigger_dag"
+	task_type_external_dag_sensor = "external_dag_sensor"
+	task_type_sensor              = "sensor"
+	task_type_mapped              = "mapped"
+	task_type_group               = "group"
+	task_type_short_circuit       = "short_circuit"
+
+	job_mode_singleton = "singleton"
+)
+
+var (
+	path_definition_schema = "./assets/jsonschema/v1/dag/definition_schema.json"
+	file_extensions        = []string{".yaml", ".yml", ".json"}
+)
+
+type ValidationError struct {
+	File   string
+	Errors []string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("dag definition %s is invalid:\n - %s", e.File, strings.Join(e.Errors, "\n - "))
+}
+
+/* errors of every rejected file in directory */
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	var messages = make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+/* scheduler of definition file, checksum is used for check file was changed */
+type File struct {
+	Path      string
+	Checksum  string
+	Scheduler *scheduler.SchedulerInstance
+}
+
+type Loader struct {
+	schema *gojsonschema.Schema
+}
+
+/* schema is loaded on every reload, error is returned for keep current definition when schema can not be loaded */
+func NewLoader() (Loader, error) {
+	bu, err := ioutil.ReadFile(path_definition_schema)
+	if err != nil {
+		return Loader{}, fmt.Errorf("failed to load schema of dag definition: %s", err.Error())
+	}
+	loader := gojsonschema.NewSchemaLoader()
+	loader.Draft = gojsonschema.Draft7
+	loader.AutoDetect = false
+	schema, err := loader.Compile(gojsonschema.NewBytesLoader(bu))
+	if err != nil {
+		return Loader{}, fmt.Errorf("failed to compile schema of dag definition: %s", err.Error())
+	}
+	return Loader{schema: schema}, nil
+}
+
+func isDefinitionFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, extension := range file_extensions {
+		if ext == extension {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+load every definition file on directory (not recursive), invalid file will be rejected
+and return in ValidationErrors together with scheduler of valid files
+*/
+func (l Loader) LoadDirectory(dir string) ([]File, error) {
+	var files = make([]File, 0)
+	var errs = make(ValidationErrors, 0)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	var names = map[string]string{}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !isDefinitionFile(path) {
+			continue
+		}
+		bu, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, ValidationError{File: path, Errors: []string{err.Error()}})
+			continue
+		}
+		schedulerInstance, err := l.Load(path, bu)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if file, ok := names[schedulerInstance.GetName()]; ok {
+			errs = append(errs, ValidationError{File: path, Errors: []string{fmt.Sprintf("name: scheduler %s was defined in %s", schedulerInstance.GetName(), file)}})
+			continue
+		}
+		names[schedulerInstance.GetName()] = path
+		checksum := sha256.Sum256(bu)
+		files = append(files, File{Path: path, Checksum: hex.EncodeToString(checksum[:]), Scheduler: schedulerInstance})
+	}
+
+	if len(errs) > 0 {
+		return files, errs
+	}
+	return files, nil
+}
+
+func (l Loader) LoadFile(path string) (*scheduler.SchedulerInstance, error) {
+	bu, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.Load(path, bu)
+}
+
+/* parse and validate definition, name is used in error message */
+func (l Loader) Load(name string, bu []byte) (*scheduler.SchedulerInstance, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(bu, &raw); err != nil {
+		return nil, ValidationError{File: name, Errors: []string{err.Error()}}
+	}
+	/* yaml is superset of json, convert to json for validate and decode with json tag */
+	bu, err := json.Marshal(raw)
+	if err != nil {
+		return nil, ValidationError{File: name, Errors: []string{err.Error()}}
+	}
+
+	result, err := l.schema.Validate(gojsonschema.NewBytesLoader(bu))
+	if err != nil {
+		return nil, ValidationError{File: name, Errors: []string{err.Error()}}
+	}
+	if !result.Valid() {
+		var messages = make([]string, 0)
+		for _, item := range result.Errors() {
+			/* message of condition schema was duplicated with message of required field */
+			switch item.Type() {
+			case "condition_then", "number_all_of":
+				continue
+			}
+			field := item.Field()
+			if field == "(root)" {
+				field = cast.ToString(item.Details()["property"])
+			}
+			messages = append(messages, fmt.Sprintf("%s: %s", field, item.Description()))
+		}
+		return nil, ValidationError{File: name, Errors: messages}
+	}
+
+	var definition DagDefinition
+	if err := json.Unmarshal(bu, &definition); err != nil {
+		return nil, ValidationError{File: name, Errors: []string{err.Error()}}
+	}
+
+	b := &builder{errors: make([]string, 0), groupIndexes: make(map[string]int)}
+	schedulerInstance := b.build(definition)
+	if len(b.errors) > 0 {
+		return nil, ValidationError{File: name, Errors: b.errors}
+	}
+	return schedulerInstance, nil
+}
+
+/* builder collect every error of definition instead of return first error */
+type builder struct {
+	errors       []string
+	groups       []TaskGroupDefinition
+	groupIndexes map[string]int // index of task group by name
+}
+
+/* task or group which is referenced by depends_on, depends_on of group is root and upstream of group is leaf */
+type groupUnit struct {
+	task  task.Execution
+	group *task.TaskGroup
+}
+
+func (u groupUnit) roots() []task.Execution {
+	if u.group != nil {
+		return u.group.GetRoots()
+	}
+	return []task.Execution{u.task}
+}
+
+func (u groupUnit) leaves() []task.Execution {
+	if u.group != nil {
+		return u.group.GetLeaves()
+	}
+	return []task.Execution{u.task}
+}
+
+func (b *builder) addError(field string, format string, args ...interface{}) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %s", field, fmt.Sprintf(format, args...)))
+}
+
+func (b *builder) parseDuration(field string, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		b.addError(field, err.Error())
+	}
+	return duration
+}
+
+func (b *builder) build(definition DagDefinition) *scheduler.SchedulerInstance {
+	config := b.buildConfig(definition.Config)
+	job := scheduler.NewJob(definition.Arguments)
+
+	var groups = make(map[string]int)
+	for index, groupDefinition := range definition.TaskGroups {
+		if _, ok := groups[groupDefinition.Name]; ok {
+			b.addError(fmt.Sprintf("task_groups.%d.name", index), "task group %s is duplicated", groupDefinition.Name)
+			continue
+		}
+		groups[groupDefinition.Name] = index
+	}
+	b.groups = definition.TaskGroups
+	b.groupIndexes = groups
+
+	var units = make(map[string]groupUnit)
+	for index, taskDefinition := range definition.Tasks {
+		field := fmt.Sprintf("tasks.%d", index)
+		if _, ok := units[taskDefinition.Name]; ok {
+			b.addError(field+".name", "task %s is duplicated", taskDefinition.Name)
+			continue
+		}
+		if taskDefinition.Type == task_type_group {
+			group := b.buildGroup(field, taskDefinition, map[string]bool{})
+			if group == nil {
+				continue
+			}
+			embedded, err := job.AddGroup(group)
+			if err != nil {
+				b.addError(field, err.Error())
+				continue
+			}
+			units[taskDefinition.Name] = groupUnit{group: embedded}
+			continue
+		}
+		taskExecution := b.buildTask(field, taskDefinition)
+		if taskExecution == nil {
+			continue
+		}
+		units[taskDefinition.Name] = groupUnit{task: taskExecution}
+		job.AddTask(taskExecution)
+	}
+
+	for index, taskDefinition := range definition.Tasks {
+		downstream, ok := units[taskDefinition.Name]
+		if !ok {
+			continue
+		}
+		for _, name := range taskDefinition.DependsOn {
+			upstream, ok := units[name]
+			if !ok {
+				b.addError(fmt.Sprintf("tasks.%d.depends_on", index), "task %s was not defined", name)
+				continue
+			}
+			for _, downstreamTask := range downstream.roots() {
+				job.SetUpstream(downstreamTask, upstream.leaves()...)
+			}
+		}
+	}
+	if definition.CronjobExpression != "" {
+		if _, err := cron.ParseStandard(definition.CronjobExpression); err != nil {
+			b.addError("cronjob_expression", err.Error())
+		}
+	}
+	if len(b.errors) > 0 {
+		return nil
+	}
+
+	schedulerInstance := scheduler.NewScheduler(definition.CronjobExpression, definition.Name, definition.Description, config)
+	if err := schedulerInstance.RegisterJob(job); err != nil {
+		b.addError("tasks", err.Error())
+		return nil
+	}
+	return schedulerInstance
+}
+
+func (b *builder) buildConfig(definition ConfigDefinition) scheduler.SchedulerConfig {
+	config := scheduler.NewDefaultSchedulerConfig()
+	if definition.MaxActiveConcurrent > 0 {
+		config.MaxActiveConcurrent = definition.MaxActiveConcurrent
+	}
+	config.RetryTimes = definition.RetryTimes
+	config.RetryDelay = b.parseDuration("config.retry_delay", definition.RetryDelay)
+	config.JobTimeout = b.parseDuration("config.job_timeout", definition.JobTimeout)
+	config.TaskTimeout = b.parseDuration("config.task_timeout", definition.TaskTimeout)
+	if definition.JobMode == job_mode_singleton {
+		config.JobMode = constants.JOB_MODE_SIGNLETON
+	}
+	config.CatchUp = definition.CatchUp
+	if definition.ExecutionMode != "" {
+		config.ExecutionMode = constants.ExecutionMode(definition.ExecutionMode)
+	}
+	if definition.OrphanPolicy != "" {
+		config.OrphanPolicy = constants.OrphanPolicy(definition.OrphanPolicy)
+	}
+	if definition.MaxValueSize != nil {
+		config.MaxValueSize = *definition.MaxValueSize
+	}
+	config.OnSuccess = b.buildCallback("config.on_success", definition.OnSuccess)
+	config.OnError = b.buildCallback("config.on_error", definition.OnError)
+	return config
+}
+
+func (b *builder) buildGolangExecutor(field string, name string) executor.Execution {
+	fn, ok := getGolangFunc(name)
+	if !ok {
+		b.addError(field, "golang func %s was not registered", name)
+		return nil
+	}
+	return executor.NewGolangExecuter(fn)
+}
+
+func (b *builder) buildHttpConfig(field string, definition *HttpDefinition) executor.HttpExecutorConfig {
+	return executor.HttpExecutorConfig{
+		Method:              definition.Method,
+		Url:                 definition.Url,
+		Headers:             definition.Headers,
+		Body:                definition.Body,
+		ExpectedStatusCodes: definition.ExpectedStatusCodes,
+		Timeout:             b.parseDuration(field+".timeout", definition.Timeout),
+	}
+}
+
+func (b *builder) buildSqlConfig(definition *SqlDefinition) executor.SqlExecutorConfig {
+	return executor.SqlExecutorConfig{
+		ConnectionName: definition.Connection,
+		Query:          definition.Query,
+		FetchResult:    definition.FetchResult,
+	}
+}
+
+func (b *builder) buildExternalDagSensorConfig(field string, definition *ExternalDagSensorDefinition) executor.ExternalDagSensorConfig {
+	return executor.ExternalDagSensorConfig{
+		SchedulerName:  definition.SchedulerName,
+		ExecutionDelta: b.parseDuration(field+".execution_delta", definition.ExecutionDelta),
+		PokeInterval:   b.parseDuration(field+".poke_interval", definition.PokeInterval),
+		FailOnFailed:   definition.FailOnFailed,
+	}
+}
+
+func (b *builder) buildSensor(field string, definition *SensorDefinition) executor.Sensor {
+	switch {
+	case definition.File != nil:
+		return executor.NewFileSensor(definition.File.Pattern)
+	case definition.Sql != nil:
+		return executor.NewSqlSensor(b.buildSqlConfig(definition.Sql))
+	case definition.Http != nil:
+		return executor.NewHttpSensor(b.buildHttpConfig(field+".http", definition.Http))
+	default:
+		return executor.NewExternalDagSensor(b.buildExternalDagSensorConfig(field+".external_dag", definition.ExternalDag)).(executor.Sensor)
+	}
+}
+
+/* executor of task type which is run by task.NewTask, type of mapped task is type of executor on field mapped.type */
+func (b *builder) buildExecutor(field string, executorType string, definition TaskDefinition) executor.Execution {
+	var isMissing = map[string]bool{
+		task_type_bash:                definition.Bash == nil,
+		task_type_http:                definition.Http == nil,
+		task_type_sql:                 definition.Sql == nil,
+		task_type_golang:              definition.Golang == nil,
+		task_type_trigger_dag:         definition.TriggerDag == nil,
+		task_type_external_dag_sensor: definition.DagSensor == nil,
+	}
+	if isMissing[executorType] {
+		b.addError(field+"."+executorType, "%s is required", executorType)
+		return nil
+	}
+
+	switch executorType {
+	case task_type_bash:
+		return executor.NewBashExecutor(definition.Bash.Cmd, definition.Bash.ShowResult)
+	case task_type_http:
+		return executor.NewHttpExecutor(b.buildHttpConfig(field+".http", definition.Http))
+	case task_type_sql:
+		return executor.NewSqlExecutor(b.buildSqlConfig(definition.Sql))
+	case task_type_golang:
+		return b.buildGolangExecutor(field+".golang.func", definition.Golang.Func)
+	case task_type_trigger_dag:
+		return executor.NewTriggerDagExecutor(executor.TriggerDagExecutorConfig{
+			SchedulerName: definition.TriggerDag.SchedulerName,
+			Config:        definition.TriggerDag.Config,
+			WaitForFinish: definition.TriggerDag.WaitForFinish,
+			PokeInterval:  b.parseDuration(field+".trigger_dag.poke_interval", definition.TriggerDag.PokeInterval),
+		})
+	case task_type_external_dag_sensor:
+		return executor.NewExternalDagSensor(b.buildExternalDagSensorConfig(field+".external_dag_sensor", definition.DagSensor))
+	}
+	return nil
+}
+
+/* build group by task type group, visiting is used for check group which embedded itself */
+func (b *builder) buildGroup(field string, definition TaskDefinition, visiting map[string]bool) *task.TaskGroup {
+	if strings.Contains(definition.Name, ".") {
+		b.addError(field+".name", "name of task type group can not contain '.'")
+		return nil
+	}
+	if definition.TriggerRule != "" {
+		b.addError(field+".trigger_rule", "trigger_rule of task type group is not supported")
+	}
+	if definition.Options != nil {
+		b.addError(field+".options", "options of task type group is not supported")
+	}
+	groupIndex, ok := b.groupIndexes[definition.Group]
+	if !ok {
+		b.addError(field+".group", "task group %s was not defined", definition.Group)
+		return nil
+	}
+	if visiting[definition.Group] {
+		b.addError(field+".group", "task group %s can not embed itself", definition.Group)
+		return nil
+	}
+	visiting[definition.Group] = true
+	defer delete(visiting, definition.Group)
+
+	var group = task.NewTaskGroup(definition.Name)
+	var groupDefinition = b.groups[groupIndex]
+	var units = make(map[string]groupUnit)
+	var isValid = true
+	for index, taskDefinition := range groupDefinition.Tasks {
+		taskField := fmt.Sprintf("task_groups.%d.tasks.%d", groupIndex, index)
+		if _, ok := units[taskDefinition.Name]; ok {
+			b.addError(taskField+".name", "task %s is duplicated", taskDefinition.Name)
+			isValid = false
+			continue
+		}
+		if taskDefinition.Type == task_type_group {
+			subgroup := b.buildGroup(taskField, taskDefinition, visiting)
+			if subgroup == nil {
+				isValid = false
+				continue
+			}
+			group.AddGroup(subgroup)
+			units[taskDefinition.Name] = groupUnit{group: subgroup}
+			continue
+		}
+		taskExecution := b.buildTask(taskField, taskDefinition)
+		if taskExecution == nil {
+			isValid = false
+			continue
+		}
+		group.AddTask(taskExecution)
+		units[taskDefinition.Name] = groupUnit{task: taskExecution}
+	}
+
+	for index, taskDefinition := range groupDefinition.Tasks {
+		downstream, ok := units[taskDefinition.Name]
+		if !ok {
+			continue
+		}
+		for _, name := range taskDefinition.DependsOn {
+			upstream, ok := units[name]
+			if !ok {
+				b.addError(fmt.Sprintf("task_groups.%d.tasks.%d.depends_on", groupIndex, index), "task %s was not defined in task group %s", name, groupDefinition.Name)
+				isValid = false
+				continue
+			}
+			for _, downstreamTask := range downstream.roots() {
+				group.SetUpstream(downstreamTask, upstream.leaves()...)
+			}
+		}
+	}
+	if !isValid {
+		return nil
+	}
+	return group
+}
+
+func (b *builder) buildTask(field string, definition TaskDefinition) task.Execution {
+	var taskExecution task.Execution
+	switch definition.Type {
+	case task_type_branch:
+		fn := b.buildGolangExecutor(field+".branch.func", definition.Branch.Func)
+		var pipes = make(map[string][]task.Execution)
+		for branchName, taskDefinitions := range definition.Branch.Branches {
+			pipes[branchName] = make([]task.Execution, 0, len(taskDefinitions))
+			for index, taskDefinition := range taskDefinitions {
+				if len(taskDefinition.DependsOn) > 0 {
+					b.addError(fmt.Sprintf("%s.branch.branches.%s.%d.depends_on", field, branchName, index), "task in branch is run by order, depends_on is not supported")
+				}
+				if branchTask := b.buildTask(fmt.Sprintf("%s.branch.branches.%s.%d", field, branchName, index), taskDefinition); branchTask != nil {
+					pipes[branchName] = append(pipes[branchName], branchTask)
+				}
+			}
+		}
+		if fn == nil {
+			return nil
+		}
+		taskExecution = task.NewTaskBranch(definition.Name, fn, task.NewTaskBranchPipeline(pipes))
+		if definition.Branch.JoinMode != "" {
+			taskExecution.(*task.TaskBranch).SetJoinMode(constants.BranchJoinMode(definition.Branch.JoinMode))
+		}
+	case task_type_sensor:
+		taskExecution = task.NewTaskSensor(definition.Name, b.buildSensor(field+".sensor", definition.Sensor), task.SensorConfig{
+			Mode:         constants.SensorMode(definition.Sensor.Mode),
+			PokeInterval: b.parseDuration(field+".sensor.poke_interval", definition.Sensor.PokeInterval),
+			Timeout:      b.parseDuration(field+".sensor.timeout", definition.Sensor.Timeout),
+		})
+	case task_type_mapped:
+		var isDependency = len(definition.DependsOn) == 0 // task without depends_on is run by order
+		for _, name := range definition.DependsOn {
+			isDependency = isDependency || name == definition.Mapped.Upstream
+		}
+		if !isDependency {
+			b.addError(field+".mapped.upstream", "upstream %s must be in depends_on", definition.Mapped.Upstream)
+		}
+		fn := b.buildExecutor(field, definition.Mapped.Type, definition)
+		if fn == nil {
+			return nil
+		}
+		taskExecution = task.NewTaskMapped(definition.Name, fn, task.MappedConfig{
+			Upstream:  definition.Mapped.Upstream,
+			MaxActive: definition.Mapped.MaxActive,
+		})
+	case task_type_short_circuit:
+		fn := b.buildGolangExecutor(field+".short_circuit.func", definition.Circuit.Func)
+		if fn == nil {
+			return nil
+		}
+		taskExecution = task.NewTaskShortCircuit(definition.Name, fn)
+	case task_type_bash, task_type_http, task_type_sql, task_type_golang, task_type_trigger_dag, task_type_external_dag_sensor:
+		fn := b.buildExecutor(field, definition.Type, definition)
+		if fn == nil {
+			return nil
+		}
+		taskExecution = task.NewTask(definition.Name, fn)
+	default:
+		b.addError(field+".type", "task type %s is not supported", definition.Type)
+		return nil
+	}
+
+	if definition.TriggerRule != "" {
+		taskExecution.SetTriggerRule(constants.TriggerRule(definition.TriggerRule))
+	}
+	if definition.Options != nil {
+		taskExecution.SetOptions(b.buildTaskOptions(field+".options", definition.Options))
+	}
+	return taskExecution
+}
+
+func (b *builder) buildTaskOptions(field string, definition *TaskOptionsDefinition) task.TaskOptions {
+	var options = task.TaskOptions{
+		RetryTimes:    definition.RetryTimes,
+		RetryBackoff:  constants.RetryBackoff(definition.RetryBackoff),
+		MaxRetryDelay: b.parseDuration(field+".max_retry_delay", definition.MaxRetryDelay),
+		SLA:           b.parseDuration(field+".sla", definition.SLA),
+		OnTaskSuccess: b.buildCallback(field+".on_success", definition.OnSuccess),
+		OnTaskFailure: b.buildCallback(field+".on_failure", definition.OnFailure),
+		OnTaskRetry:   b.buildCallback(field+".on_retry", definition.OnRetry),
+		OnSLAMiss:     b.buildCallback(field+".on_sla_miss", definition.OnSLAMiss),
+	}
+	if definition.RetryDelay != nil {
+		delay := b.parseDuration(field+".retry_delay", *definition.RetryDelay)
+		options.RetryDelay = &delay
+	}
+	if definition.Timeout != nil {
+		timeout := b.parseDuration(field+".timeout", *definition.Timeout)
+		options.Timeout = &timeout
+	}
+	return options
+}
+
+/* empty name is no callback */
+func (b *builder) buildCallback(field string, name string) func(ctx context.Context) error {
+	if name == "" {
+		return nil
+	}
+	fn, ok := getCallback(name)
+	if !ok {
+		b.addError(field, "callback %s was not registered", name)
+	}
+	return fn
+}
@@ -0,0 +1,144 @@
+package definition
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestLoader(t *testing.T) Loader {
+	path := path_definition_schema
+	path_definition_schema = "../../assets/jsonschema/v1/dag/definition_schema.json"
+	defer func() { path_definition_schema = path }()
+	loader, err := NewLoader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loader
+}
+
+func TestNewLoaderWithoutSchema(t *testing.T) {
+	path := path_definition_schema
+	path_definition_schema = "./not_found.json"
+	defer func() { path_definition_schema = path }()
+	if _, err := NewLoader(); err == nil || !strings.Contains(err.Error(), "failed to load schema") {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+}
+
+func TestLoadYamlAndJson(t *testing.T) {
+	loader := newTestLoader(t)
+	cases := []struct {
+		name       string
+		definition string
+	}{
+		{name: "yaml", definition: `
+name: test_yaml
+config:
+  retry_delay: 5s
+tasks:
+  - name: a
+    type: bash
+    bash:
+      cmd: echo a
+  - name: b
+    type: bash
+    depends_on: [a]
+    bash:
+      cmd: echo b
+`},
+		{name: "json", definition: `{
+    "name": "test_json",
+    "config": {"retry_delay": "5s"},
+    "tasks": [
+        {"name": "a", "type": "bash", "bash": {"cmd": "echo a"}},
+        {"name": "b", "type": "bash", "depends_on": ["a"], "bash": {"cmd": "echo b"}}
+    ]
+}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedulerInstance, err := loader.Load(tc.name, []byte(tc.definition))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schedulerInstance.GetName() != "test_"+tc.name {
+				t.Fatalf("unexpected name %s", schedulerInstance.GetName())
+			}
+			bu, _ := schedulerInstance.MarshalJSON()
+			var result struct {
+				Tasks []map[string]interface{} `json:"tasks"`
+				Edges []interface{}            `json:"edges"`
+			}
+			json.Unmarshal(bu, &result)
+			if len(result.Tasks) != 2 || len(result.Edges) != 1 {
+				t.Fatalf("expected 2 tasks and 1 edge, got %d tasks and %d edges", len(result.Tasks), len(result.Edges))
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	loader := newTestLoader(t)
+	cases := []struct {
+		name       string
+		definition string
+		err        string
+	}{
+		{name: "invalid yaml", definition: "name: [", err: "yaml"},
+		{name: "missing name", definition: "tasks: []", err: "name"},
+		{name: "unknown task type", definition: "name: x\ntasks:\n  - {name: a, type: python}", err: "tasks.0.type"},
+		{name: "unknown executor type of mapped task", definition: "name: x\ntasks:\n  - {name: a, type: mapped, mapped: {type: python, upstream: b}}", err: "tasks.0.mapped.type"},
+		{name: "missing executor", definition: "name: x\ntasks:\n  - {name: a, type: bash}", err: "bash"},
+		{name: "invalid duration", definition: "name: x\nconfig: {retry_delay: soon}\ntasks:\n  - {name: a, type: bash, bash: {cmd: echo}}", err: "config.retry_delay"},
+		{name: "golang func not registered", definition: "name: x\ntasks:\n  - {name: a, type: golang, golang: {func: not_registered}}", err: "golang func not_registered was not registered"},
+		{name: "depends on undefined task", definition: "name: x\ntasks:\n  - {name: a, type: bash, depends_on: [b], bash: {cmd: echo}}", err: "task b was not defined"},
+		{name: "invalid cron", definition: "name: x\ncronjob_expression: every minute\ntasks:\n  - {name: a, type: bash, bash: {cmd: echo}}", err: "cronjob_expression"},
+		{name: "cycle", definition: "name: x\ntasks:\n  - {name: a, type: bash, depends_on: [b], bash: {cmd: echo}}\n  - {name: b, type: bash, depends_on: [a], bash: {cmd: echo}}", err: "cycle detected"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loader.Load(tc.name, []byte(tc.definition))
+			if _, ok := err.(ValidationError); !ok {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error contains %q, got %s", tc.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	loader := newTestLoader(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml":     "name: a\ntasks:\n  - {name: a, type: bash, bash: {cmd: echo}}",
+		"b.json":     `{"name": "b", "tasks": [{"name": "b", "type": "python"}]}`,
+		"c.yml":      "name: a\ntasks:\n  - {name: c, type: bash, bash: {cmd: echo}}",
+		"readme.txt": "not definition",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := loader.LoadDirectory(dir)
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected 2 rejected files, got %v", err)
+	}
+	if !strings.Contains(errs[1].Error(), "scheduler a was defined in") {
+		t.Fatalf("expected duplicated name error, got %s", errs[1].Error())
+	}
+	if len(loaded) != 1 || loaded[0].Scheduler.GetName() != "a" || loaded[0].Checksum == "" {
+		t.Fatalf("expected only a.yaml was loaded, got %v", loaded)
+	}
+
+	if loaded, err := loader.LoadDirectory(filepath.Join(dir, "not_found")); err != nil || len(loaded) != 0 {
+		t.Fatalf("expected empty result of directory which was not found, got %v %v", loaded, err)
+	}
+}
@@ -0,0 +1,37 @@
+package definition
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	golangFuncs = new(sync.Map)
+	callbacks   = new(sync.Map)
+)
+
+/* register golang func which can be referenced by name from golang or branch task in dag definition */
+func RegisterGolangFunc(name string, fn func(ctx context.Context) (interface{}, error)) {
+	golangFuncs.Store(name, fn)
+}
+
+/* register callback which can be referenced by name from on_success or on_error in dag definition */
+func RegisterCallback(name string, fn func(ctx context.Context) error) {
+	callbacks.Store(name, fn)
+}
+
+func getGolangFunc(name string) (func(ctx context.Context) (interface{}, error), bool) {
+	val, ok := golangFuncs.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return val.(func(ctx context.Context) (interface{}, error)), true
+}
+
+func getCallback(name string) (func(ctx context.Context) error, bool) {
+	val, ok := callbacks.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return val.(func(ctx context.Context) error), true
+}
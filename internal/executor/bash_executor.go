@@ -1,9 +1,11 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os/exec"
+	"syscall"
 )
 
 type BashExecutor struct {
@@ -23,14 +25,40 @@ func (b BashExecutor) GetName() string {
 }
 
 func (b BashExecutor) Execute(ctx context.Context) (interface{}, error) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
 	cmd := exec.Command("bash", "-c", b.cmd)
-	output, err := cmd.Output()
-	if err != nil {
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	/* run on own process group for kill all subprocess when context was done */
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
 		return nil, err
 	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return nil, ctx.Err()
+	case err := <-done:
+		if err != nil {
+			if stderr.Len() > 0 {
+				return nil, fmt.Errorf("%s: %s", err.Error(), stderr.String())
+			}
+			return nil, err
+		}
+	}
+
 	if b.showResult {
 		fmt.Println(cmd.String())
-		fmt.Println(string(output))
+		fmt.Println(stdout.String())
 	}
-	return string(output), nil
+	return stdout.String(), nil
 }
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+const (
+	default_external_dag_poke_interval = 30 * time.Second
+)
+
+type ExternalDagSensorConfig struct {
+	SchedulerName  string
+	ExecutionDelta time.Duration // accept job which was started since execute datetime of current job minus delta
+	PokeInterval   time.Duration // interval of check job, default is 30 second
+	FailOnFailed   bool          // lastest job which was failed is returned as error instead of waiting
+}
+
+/*
+wait until another scheduler has job which was success, deadline is task timeout of scheduler.
+it is also Sensor which is able to use with task.NewTaskSensor
+*/
+type ExternalDagSensor struct {
+	config ExternalDagSensorConfig
+}
+
+func NewExternalDagSensor(config ExternalDagSensorConfig) Execution {
+	if config.PokeInterval <= 0 {
+		config.PokeInterval = default_external_dag_poke_interval
+	}
+	return &ExternalDagSensor{config: config}
+}
+
+func (e ExternalDagSensor) GetName() string {
+	return "ExternalDagSensor"
+}
+
+func (e ExternalDagSensor) poke(ctx context.Context, runner dagRunner, since time.Time) (string, bool, error) {
+	job, err := runner.GetLastestJob(ctx, e.config.SchedulerName, since, constants.JOB_STATUS_SUCCESS)
+	if err != nil {
+		return "", false, err
+	}
+	if job != nil {
+		return job.JobId, true, nil
+	}
+	if !e.config.FailOnFailed {
+		return "", false, nil
+	}
+
+	job, err = runner.GetLastestJob(ctx, e.config.SchedulerName, since)
+	if err != nil {
+		return "", false, err
+	}
+	if job != nil && isFinishedJobStatus(job.Status) {
+		return "", false, fmt.Errorf("job %s of scheduler %s was finished with status %s", job.JobId, e.config.SchedulerName, job.Status)
+	}
+	return "", false, nil
+}
+
+/* return job id which was success */
+func (e ExternalDagSensor) Poke(ctx context.Context) (interface{}, bool, error) {
+	runner, err := getDagRunner(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	since := runner.GetExecuteDatetime().Add(-e.config.ExecutionDelta)
+
+	jobId, ok, err := e.poke(ctx, runner, since)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return map[string]interface{}{
+		"scheduler_name": e.config.SchedulerName,
+		"job_id":         jobId,
+	}, true, nil
+}
+
+func (e ExternalDagSensor) Execute(ctx context.Context) (interface{}, error) {
+	ticker := time.NewTicker(e.config.PokeInterval)
+	defer ticker.Stop()
+	for {
+		value, ok, err := e.Poke(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return value, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
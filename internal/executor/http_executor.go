@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type HttpExecutorConfig struct {
+	Method              string
+	Url                 string            // support template
+	Headers             map[string]string // support template on value
+	Body                string            // support template
+	ExpectedStatusCodes []int             // empty is accept status code 2xx
+	Timeout             time.Duration     // 0 is no timeout
+}
+
+type HttpExecutor struct {
+	config HttpExecutorConfig
+	client *http.Client
+}
+
+func NewHttpExecutor(config HttpExecutorConfig) Execution {
+	if config.Method == "" {
+		config.Method = http.MethodGet
+	}
+	return &HttpExecutor{
+		config: config,
+		client: &http.Client{},
+	}
+}
+
+func (h HttpExecutor) GetName() string {
+	return "HttpExecutor"
+}
+
+func (h HttpExecutor) isExpectedStatusCode(statusCode int) bool {
+	if len(h.config.ExpectedStatusCodes) == 0 {
+		return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+	}
+	for _, code := range h.config.ExpectedStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (h HttpExecutor) newRequest(ctx context.Context) (*http.Request, error) {
+	url, err := renderTemplate(ctx, "url", h.config.Url)
+	if err != nil {
+		return nil, err
+	}
+	body, err := renderTemplate(ctx, "body", h.config.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(h.config.Method), url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range h.config.Headers {
+		val, err := renderTemplate(ctx, key, value)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(key, val)
+	}
+	return req, nil
+}
+
+/* response body will be decoded when it is json otherwise it is string */
+func (h HttpExecutor) Execute(ctx context.Context) (interface{}, error) {
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.config.Timeout)
+		defer cancel()
+	}
+
+	req, err := h.newRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bu, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isExpectedStatusCode(resp.StatusCode) {
+		return nil, fmt.Errorf("%s %s response unexpected status code %d: %s", req.Method, req.URL.String(), resp.StatusCode, string(bu))
+	}
+
+	var data interface{}
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") || json.Valid(bu) {
+		if err := json.Unmarshal(bu, &data); err == nil {
+			return data, nil
+		}
+	}
+	return string(bu), nil
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+type fakeRunner struct {
+	parameter  *sync.Map
+	taskValues map[string]interface{}
+}
+
+func (f fakeRunner) GetArguments() *sync.Map     { return new(sync.Map) }
+func (f fakeRunner) GetParameter() *sync.Map     { return f.parameter }
+func (f fakeRunner) GetTriggerConfig() *sync.Map { return new(sync.Map) }
+func (f fakeRunner) GetTaskValue(taskId string) (interface{}, bool) {
+	value, ok := f.taskValues[taskId]
+	return value, ok
+}
+
+func TestHttpExecutorStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+		case "/notfound":
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		path     string
+		expected []int
+		isError  bool
+	}{
+		{path: "/ok"},
+		{path: "/created"},
+		{path: "/notfound", isError: true},
+		{path: "/notfound", expected: []int{http.StatusNotFound}},
+		{path: "/ok", expected: []int{http.StatusCreated}, isError: true},
+	}
+	for _, tc := range cases {
+		executor := NewHttpExecutor(HttpExecutorConfig{Url: server.URL + tc.path, ExpectedStatusCodes: tc.expected})
+		value, err := executor.Execute(context.Background())
+		if tc.isError {
+			if err == nil || !strings.Contains(err.Error(), "unexpected status code") {
+				t.Errorf("%s %v: expected status code error, got %v", tc.path, tc.expected, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: unexpected error %v", tc.path, tc.expected, err)
+			continue
+		}
+		if value != "body" {
+			t.Errorf("%s %v: expected body, got %v", tc.path, tc.expected, value)
+		}
+	}
+}
+
+func TestHttpExecutorDecodeJson(t *testing.T) {
+	var received = make(chan *http.Request, 1)
+	var receivedBody = make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bu, _ := io.ReadAll(r.Body)
+		received <- r
+		receivedBody <- string(bu)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "items": ["a", "b"]}`))
+	}))
+	defer server.Close()
+
+	parameter := new(sync.Map)
+	parameter.Store("token", "secret")
+	runner := fakeRunner{parameter: parameter, taskValues: map[string]interface{}{"upstream": "value"}}
+	ctx := context.WithValue(context.Background(), constants.JOB_RUNNER_INSTANCE_KEY, runner)
+
+	executor := NewHttpExecutor(HttpExecutorConfig{
+		Method:  "post",
+		Url:     server.URL + "/items/{{ taskValue \"upstream\" }}",
+		Headers: map[string]string{"Authorization": "Bearer {{ .Parameter.token }}"},
+		Body:    `{"name": "{{ taskValue "upstream" }}"}`,
+	})
+	value, err := executor.Execute(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-received
+	if req.Method != http.MethodPost || req.URL.Path != "/items/value" || req.Header.Get("Authorization") != "Bearer secret" {
+		t.Fatalf("unexpected request %s %s %s", req.Method, req.URL.Path, req.Header.Get("Authorization"))
+	}
+	if body := <-receivedBody; body != `{"name": "value"}` {
+		t.Fatalf("unexpected request body %s", body)
+	}
+	data, ok := value.(map[string]interface{})
+	if !ok || data["id"] != float64(1) || len(data["items"].([]interface{})) != 2 {
+		t.Fatalf("unexpected value %#v", value)
+	}
+}
+
+func TestHttpExecutorTimeout(t *testing.T) {
+	var done = make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	executor := NewHttpExecutor(HttpExecutorConfig{Url: server.URL, Timeout: 50 * time.Millisecond})
+	start := time.Now()
+	_, err := executor.Execute(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("request was not cancelled by timeout, took %s", elapsed)
+	}
+}
@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+)
+
+/* condition of sensor task, ok is true when condition was met */
+type Sensor interface {
+	GetName() string
+	Poke(ctx context.Context) (value interface{}, ok bool, err error)
+}
+
+type FileSensor struct {
+	pattern string
+}
+
+/* wait until any file match pattern, support template and glob pattern */
+func NewFileSensor(pattern string) Sensor {
+	return &FileSensor{pattern: pattern}
+}
+
+func (f FileSensor) GetName() string {
+	return "FileSensor"
+}
+
+/* return path of matched files */
+func (f FileSensor) Poke(ctx context.Context) (interface{}, bool, error) {
+	pattern, err := renderTemplate(ctx, "pattern", f.pattern)
+	if err != nil {
+		return nil, false, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(matches) == 0 {
+		return nil, false, nil
+	}
+	return matches, true, nil
+}
+
+type SqlSensor struct {
+	executor SqlExecutor
+}
+
+/* wait until query return any row */
+func NewSqlSensor(config SqlExecutorConfig) Sensor {
+	config.FetchResult = true
+	return &SqlSensor{executor: SqlExecutor{config: config}}
+}
+
+func (s SqlSensor) GetName() string {
+	return "SqlSensor"
+}
+
+/* return result rows */
+func (s SqlSensor) Poke(ctx context.Context) (interface{}, bool, error) {
+	value, err := s.executor.Execute(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	rows, _ := value.([]map[string]interface{})
+	if len(rows) == 0 {
+		return nil, false, nil
+	}
+	return rows, true, nil
+}
+
+type HttpSensor struct {
+	executor HttpExecutor
+}
+
+/* wait until endpoint response expected status code, endpoint which can not be connected is not met */
+func NewHttpSensor(config HttpExecutorConfig) Sensor {
+	return &HttpSensor{executor: *NewHttpExecutor(config).(*HttpExecutor)}
+}
+
+func (h HttpSensor) GetName() string {
+	return "HttpSensor"
+}
+
+/* return status code of response */
+func (h HttpSensor) Poke(ctx context.Context) (interface{}, bool, error) {
+	if h.executor.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.executor.config.Timeout)
+		defer cancel()
+	}
+
+	req, err := h.executor.newRequest(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := h.executor.client.Do(req)
+	if err != nil {
+		return nil, false, nil
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if !h.executor.isExpectedStatusCode(resp.StatusCode) {
+		return nil, false, nil
+	}
+	return resp.StatusCode, true, nil
+}
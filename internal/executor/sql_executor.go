@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/BlackMocca/sqlx"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+const (
+	sql_bind_prefix_arguments      = "args."
+	sql_bind_prefix_trigger_config = "config."
+	sql_bind_prefix_parameter      = "param."
+	sql_bind_prefix_task           = "task."
+	sql_bind_map_index             = "map.index"
+	sql_bind_map_item              = "map.item"
+)
+
+var (
+	sqlBindTaskPattern = regexp.MustCompile(`(?:^|[^:]):task\.([\p{L}\p{N}_.]+)`)
+)
+
+type SqlExecutorConfig struct {
+	ConnectionName string // name of registered sql connection, empty is default connection
+	/*
+		statement or script, support named parameter
+		:args.<key>    value from GetArguments
+		:config.<key>  value from GetTriggerConfig
+		:param.<key>   value from GetParameter
+		:task.<name>   value from upstream task, task in same pipeline of branch is found before task on root of job
+		:map.index     index of item on instance of mapped task
+		:map.item      item on instance of mapped task
+		query which has named parameter must escape literal ':' with '::'
+		(use CAST(value AS type) instead of value::type)
+	*/
+	Query       string
+	FetchResult bool // true is return result rows, false is return rows affected
+}
+
+type SqlExecutor struct {
+	config SqlExecutorConfig
+}
+
+func NewSqlExecutor(config SqlExecutorConfig) Execution {
+	return &SqlExecutor{config: config}
+}
+
+func (s SqlExecutor) GetName() string {
+	return "SqlExecutor"
+}
+
+func (s SqlExecutor) hasNamedParameter() bool {
+	for _, prefix := range []string{sql_bind_prefix_arguments, sql_bind_prefix_trigger_config, sql_bind_prefix_parameter, sql_bind_prefix_task, sql_bind_map_index, sql_bind_map_item} {
+		if strings.Contains(s.config.Query, ":"+prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s SqlExecutor) getBindValues(ctx context.Context) map[string]interface{} {
+	var values = map[string]interface{}{
+		sql_bind_map_index: ctx.Value(constants.MAP_INDEX_KEY),
+		sql_bind_map_item:  ctx.Value(constants.MAP_ITEM_KEY),
+	}
+	var runner = getRunnerValue(ctx)
+	if runner == nil {
+		return values
+	}
+
+	var prefixs = map[string]map[string]interface{}{
+		sql_bind_prefix_arguments:      constants.PARSE_SYNC_MAP_TO_MAP(runner.GetArguments()),
+		sql_bind_prefix_trigger_config: constants.PARSE_SYNC_MAP_TO_MAP(runner.GetTriggerConfig()),
+		sql_bind_prefix_parameter:      constants.PARSE_SYNC_MAP_TO_MAP(runner.GetParameter()),
+	}
+	for prefix, m := range prefixs {
+		for key, value := range m {
+			values[prefix+key] = value
+		}
+	}
+	/* load value of upstream task only when it is used */
+	for _, match := range sqlBindTaskPattern.FindAllStringSubmatch(s.config.Query, -1) {
+		if value, ok := getTaskValue(ctx, runner, match[1]); ok {
+			values[sql_bind_prefix_task+match[1]] = value
+		}
+	}
+	return values
+}
+
+/* bind named parameter, query without named parameter will be executed as script */
+func (s SqlExecutor) bind(ctx context.Context, db *sqlx.DB) (string, []interface{}, error) {
+	if !s.hasNamedParameter() {
+		return s.config.Query, nil, nil
+	}
+
+	query, args, err := sqlx.Named(s.config.Query, s.getBindValues(ctx))
+	if err != nil {
+		return "", nil, err
+	}
+	return db.Rebind(query), args, nil
+}
+
+func (s SqlExecutor) Execute(ctx context.Context) (interface{}, error) {
+	db, err := connection.GetSqlConnection(s.config.ConnectionName)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := s.bind(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.FetchResult {
+		return s.query(ctx, db, query, args)
+	}
+	return s.exec(ctx, db, query, args)
+}
+
+func (s SqlExecutor) query(ctx context.Context, db *sqlx.DB, query string, args []interface{}) (interface{}, error) {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results = make([]map[string]interface{}, 0)
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := rows.MapScan(row); err != nil {
+			return nil, err
+		}
+		for key, value := range row {
+			if bu, ok := value.([]byte); ok {
+				row[key] = string(bu)
+			}
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+func (s SqlExecutor) exec(ctx context.Context, db *sqlx.DB, query string, args []interface{}) (interface{}, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+type fakeTask string
+
+func (f fakeTask) GetId() string { return string(f) }
+
+func TestSqlExecutorBindTaskValue(t *testing.T) {
+	runner := fakeRunner{
+		parameter: new(sync.Map),
+		taskValues: map[string]interface{}{
+			"extract":            "root",
+			"load":               "root load",
+			"branch/a/extract":   "pipeline",
+			"branch/a/extract#2": "pipeline duplicated",
+			"branch/b/transform": "other pipeline",
+		},
+	}
+	executor := SqlExecutor{config: SqlExecutorConfig{Query: "SELECT :task.extract, :task.load, :task.transform"}}
+
+	cases := []struct {
+		name     string
+		taskId   string
+		expected map[string]interface{}
+	}{
+		{name: "root", taskId: "load_db", expected: map[string]interface{}{"task.extract": "root", "task.load": "root load"}},
+		{name: "pipeline", taskId: "branch/a/load_db", expected: map[string]interface{}{"task.extract": "pipeline", "task.load": "root load"}},
+		{name: "other pipeline", taskId: "branch/b/load_db", expected: map[string]interface{}{"task.extract": "root", "task.load": "root load", "task.transform": "other pipeline"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), constants.JOB_RUNNER_INSTANCE_KEY, runner)
+			ctx = context.WithValue(ctx, constants.TASK_KEY, fakeTask(tc.taskId))
+			values := executor.getBindValues(ctx)
+			for _, key := range []string{"task.extract", "task.load", "task.transform"} {
+				value, ok := values[key]
+				expected, isExpected := tc.expected[key]
+				if ok != isExpected || value != expected {
+					t.Errorf("%s: expected %v, got %v", key, expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTemplateTaskValue(t *testing.T) {
+	runner := fakeRunner{
+		parameter:  new(sync.Map),
+		taskValues: map[string]interface{}{"extract": "root", "branch/a/extract": "pipeline"},
+	}
+	ctx := context.WithValue(context.Background(), constants.JOB_RUNNER_INSTANCE_KEY, runner)
+	ctx = context.WithValue(ctx, constants.TASK_KEY, fakeTask("branch/a/load"))
+	text, err := renderTemplate(ctx, "body", `{{ taskValue "extract" }} {{ taskValue "branch/a/extract" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "pipeline pipeline" {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+/* subset of scheduler.JobRunner which executor can read without import scheduler package */
+type runnerValue interface {
+	GetArguments() *sync.Map
+	GetParameter() *sync.Map
+	GetTriggerConfig() *sync.Map
+	GetTaskValue(taskId string) (data interface{}, ok bool)
+}
+
+type templateData struct {
+	Arguments     map[string]interface{}
+	Parameter     map[string]interface{}
+	TriggerConfig map[string]interface{}
+	MapIndex      interface{} // index of item on instance of mapped task
+	MapItem       interface{} // item on instance of mapped task
+}
+
+/* task which is called, executor can not import task package */
+type taskValue interface {
+	GetId() string
+}
+
+func getRunnerValue(ctx context.Context) runnerValue {
+	if runner, ok := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(runnerValue); ok {
+		return runner
+	}
+	return nil
+}
+
+/* name of task in same pipeline of branch is resolved to id of that task (branch/pipeline/name), otherwise name is used as id */
+func getTaskValue(ctx context.Context, runner runnerValue, name string) (interface{}, bool) {
+	if current, ok := ctx.Value(constants.TASK_KEY).(taskValue); ok {
+		if index := strings.LastIndex(current.GetId(), "/"); index >= 0 {
+			if value, ok := runner.GetTaskValue(current.GetId()[:index+1] + name); ok {
+				return value, true
+			}
+		}
+	}
+	return runner.GetTaskValue(name)
+}
+
+/*
+render text with data of job runner
+{{ .Arguments.key }} {{ .Parameter.key }} {{ .TriggerConfig.key }} {{ taskValue "taskname" }} (name or id of task)
+{{ .MapIndex }} {{ .MapItem }} on instance of mapped task
+*/
+func renderTemplate(ctx context.Context, name string, text string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	var data = templateData{
+		MapIndex: ctx.Value(constants.MAP_INDEX_KEY),
+		MapItem:  ctx.Value(constants.MAP_ITEM_KEY),
+	}
+	var runner = getRunnerValue(ctx)
+	if runner != nil {
+		data.Arguments = constants.PARSE_SYNC_MAP_TO_MAP(runner.GetArguments())
+		data.Parameter = constants.PARSE_SYNC_MAP_TO_MAP(runner.GetParameter())
+		data.TriggerConfig = constants.PARSE_SYNC_MAP_TO_MAP(runner.GetTriggerConfig())
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(template.FuncMap{
+		"taskValue": func(name string) interface{} {
+			if runner == nil {
+				return nil
+			}
+			value, _ := getTaskValue(ctx, runner, name)
+			return value
+		},
+		"json": func(value interface{}) (string, error) {
+			bu, err := json.Marshal(value)
+			return string(bu), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
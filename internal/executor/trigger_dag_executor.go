@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+)
+
+const (
+	default_trigger_dag_poke_interval = 10 * time.Second
+)
+
+/* subset of scheduler.JobRunner which is able to trigger and read job of another scheduler */
+type dagRunner interface {
+	GetExecuteDatetime() time.Time
+	TriggerDag(ctx context.Context, schedulerName string, config map[string]interface{}) (jobId string, err error)
+	GetJob(ctx context.Context, jobId string) (*models.Job, error)
+	GetLastestJob(ctx context.Context, schedulerName string, since time.Time, statuses ...constants.JobStatus) (*models.Job, error)
+}
+
+func getDagRunner(ctx context.Context) (dagRunner, error) {
+	if runner, ok := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(dagRunner); ok {
+		return runner, nil
+	}
+	return nil, errors.New("job runner was not found in context")
+}
+
+func isFinishedJobStatus(status constants.JobStatus) bool {
+	switch status {
+	case constants.JOB_STATUS_SUCCESS, constants.JOB_STATUS_FAILED, constants.JOB_STATUS_TIMEOUT:
+		return true
+	}
+	return false
+}
+
+type TriggerDagExecutorConfig struct {
+	SchedulerName string
+	Config        map[string]interface{} // trigger config of job, support template on string value
+	WaitForFinish bool                   // wait until job was finished, job which was not success is returned as error
+	PokeInterval  time.Duration          // interval of check status of job when wait, default is 10 second
+}
+
+type TriggerDagExecutor struct {
+	config TriggerDagExecutorConfig
+}
+
+func NewTriggerDagExecutor(config TriggerDagExecutorConfig) Execution {
+	if config.PokeInterval <= 0 {
+		config.PokeInterval = default_trigger_dag_poke_interval
+	}
+	return &TriggerDagExecutor{config: config}
+}
+
+func (t TriggerDagExecutor) GetName() string {
+	return "TriggerDagExecutor"
+}
+
+func (t TriggerDagExecutor) renderConfig(ctx context.Context) (map[string]interface{}, error) {
+	var config = make(map[string]interface{}, len(t.config.Config))
+	for key, value := range t.config.Config {
+		if text, ok := value.(string); ok {
+			val, err := renderTemplate(ctx, key, text)
+			if err != nil {
+				return nil, err
+			}
+			value = val
+		}
+		config[key] = value
+	}
+	return config, nil
+}
+
+/* return job id and status of triggered job */
+func (t TriggerDagExecutor) Execute(ctx context.Context) (interface{}, error) {
+	runner, err := getDagRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := t.renderConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobId, err := runner.TriggerDag(ctx, t.config.SchedulerName, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = map[string]interface{}{
+		"scheduler_name": t.config.SchedulerName,
+		"job_id":         jobId,
+		"status":         constants.JOB_STATUS_WAITING,
+	}
+	if !t.config.WaitForFinish {
+		return result, nil
+	}
+
+	ticker := time.NewTicker(t.config.PokeInterval)
+	defer ticker.Stop()
+	for {
+		job, err := runner.GetJob(ctx, jobId)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil && isFinishedJobStatus(job.Status) {
+			result["status"] = job.Status
+			if job.Status != constants.JOB_STATUS_SUCCESS {
+				return nil, fmt.Errorf("job %s of scheduler %s was finished with status %s", jobId, t.config.SchedulerName, job.Status)
+			}
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
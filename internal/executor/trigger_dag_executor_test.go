@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+)
+
+/* status of job is returned by order of call, last status is kept */
+type fakeDagRunner struct {
+	fakeRunner
+	mutex           sync.Mutex
+	executeDatetime time.Time
+	triggerErr      error
+	config          map[string]interface{}
+	statuses        []constants.JobStatus
+	lastest         map[constants.JobStatus]*models.Job // lastest job by status, empty status is any status
+	since           time.Time
+}
+
+func (f *fakeDagRunner) GetExecuteDatetime() time.Time {
+	return f.executeDatetime
+}
+
+func (f *fakeDagRunner) TriggerDag(ctx context.Context, schedulerName string, config map[string]interface{}) (string, error) {
+	f.config = config
+	return "job-1", f.triggerErr
+}
+
+func (f *fakeDagRunner) GetJob(ctx context.Context, jobId string) (*models.Job, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.statuses) == 0 {
+		return nil, nil
+	}
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	return &models.Job{JobId: jobId, Status: status}, nil
+}
+
+func (f *fakeDagRunner) GetLastestJob(ctx context.Context, schedulerName string, since time.Time, statuses ...constants.JobStatus) (*models.Job, error) {
+	f.since = since
+	var status constants.JobStatus
+	if len(statuses) > 0 {
+		status = statuses[0]
+	}
+	return f.lastest[status], nil
+}
+
+func TestTriggerDagExecutor(t *testing.T) {
+	cases := []struct {
+		name       string
+		wait       bool
+		triggerErr error
+		statuses   []constants.JobStatus
+		status     constants.JobStatus
+		err        string
+	}{
+		{name: "not wait", status: constants.JOB_STATUS_WAITING},
+		{name: "wait until success", wait: true, statuses: []constants.JobStatus{"", constants.JOB_STATUS_RUNNING, constants.JOB_STATUS_SUCCESS}, status: constants.JOB_STATUS_SUCCESS},
+		{name: "wait until failed", wait: true, statuses: []constants.JobStatus{constants.JOB_STATUS_RUNNING, constants.JOB_STATUS_FAILED}, err: "was finished with status FAILED"},
+		{name: "trigger error", triggerErr: errors.New("scheduler name 'b' was paused"), err: "was paused"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := &fakeDagRunner{
+				fakeRunner: fakeRunner{parameter: new(sync.Map), taskValues: map[string]interface{}{"upstream": "value"}},
+				triggerErr: tc.triggerErr,
+				statuses:   tc.statuses,
+			}
+			ctx := context.WithValue(context.Background(), constants.JOB_RUNNER_INSTANCE_KEY, runner)
+			executor := NewTriggerDagExecutor(TriggerDagExecutorConfig{
+				SchedulerName: "b",
+				Config:        map[string]interface{}{"value": `{{ taskValue "upstream" }}`, "number": 1},
+				WaitForFinish: tc.wait,
+				PokeInterval:  time.Millisecond,
+			})
+			value, err := executor.Execute(ctx)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runner.config["value"] != "value" || runner.config["number"] != 1 {
+				t.Fatalf("trigger config was not rendered, got %v", runner.config)
+			}
+			result := value.(map[string]interface{})
+			if result["job_id"] != "job-1" || result["status"] != tc.status {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+	}
+}
+
+func TestTriggerDagExecutorCancelled(t *testing.T) {
+	runner := &fakeDagRunner{statuses: []constants.JobStatus{constants.JOB_STATUS_RUNNING}}
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), constants.JOB_RUNNER_INSTANCE_KEY, runner), 20*time.Millisecond)
+	defer cancel()
+	executor := NewTriggerDagExecutor(TriggerDagExecutorConfig{SchedulerName: "b", WaitForFinish: true, PokeInterval: time.Millisecond})
+	if _, err := executor.Execute(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestExternalDagSensor(t *testing.T) {
+	executeDatetime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	success := &models.Job{JobId: "success", Status: constants.JOB_STATUS_SUCCESS}
+	failed := &models.Job{JobId: "failed", Status: constants.JOB_STATUS_FAILED}
+	running := &models.Job{JobId: "running", Status: constants.JOB_STATUS_RUNNING}
+	cases := []struct {
+		name         string
+		failOnFailed bool
+		lastest      map[constants.JobStatus]*models.Job
+		ok           bool
+		err          string
+	}{
+		{name: "success", lastest: map[constants.JobStatus]*models.Job{constants.JOB_STATUS_SUCCESS: success, "": success}, ok: true},
+		{name: "not found", lastest: map[constants.JobStatus]*models.Job{}},
+		{name: "failed is waited", lastest: map[constants.JobStatus]*models.Job{"": failed}},
+		{name: "fail on failed", failOnFailed: true, lastest: map[constants.JobStatus]*models.Job{"": failed}, err: "was finished with status FAILED"},
+		{name: "fail on failed with running job", failOnFailed: true, lastest: map[constants.JobStatus]*models.Job{"": running}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := &fakeDagRunner{executeDatetime: executeDatetime, lastest: tc.lastest}
+			ctx := context.WithValue(context.Background(), constants.JOB_RUNNER_INSTANCE_KEY, runner)
+			sensor := NewExternalDagSensor(ExternalDagSensorConfig{SchedulerName: "b", ExecutionDelta: time.Hour, FailOnFailed: tc.failOnFailed}).(Sensor)
+			value, ok, err := sensor.Poke(ctx)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if !runner.since.Equal(executeDatetime.Add(-time.Hour)) {
+				t.Fatalf("expected job since execute datetime minus delta, got %s", runner.since)
+			}
+			if ok && value.(map[string]interface{})["job_id"] != "success" {
+				t.Fatalf("unexpected value %v", value)
+			}
+		})
+	}
+}
@@ -13,10 +13,16 @@ type Job struct {
 	Status          constants.JobStatus `json:"status" db:"status"`
 	StartDateTime   *time.Time          `json:"start_datetime" db:"start_datetime"`
 	EndDatetime     *time.Time          `json:"end_datetime" db:"end_datetime"`
+	ParameterString string              `json:"-" db:"parameter"`
+	NodeId          string              `json:"node_id" db:"node_id"`           // node which run job
+	HeartbeatAt     *time.Time          `json:"heartbeat_at" db:"heartbeat_at"` // job which heartbeat was stale is orphaned
+	Exception       string              `json:"exception" db:"exception"`
 	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time           `json:"updated_at" db:"updated_at"`
+	Parameter       interface{}         `json:"parameter" db:"-"`
 	Trigger         *Trigger            `json:"trigger" db:"-"`
 	JobRunningTasks []*JobTask          `json:"job_running_tasks" db:"-"`
+	JobTaskTree     *JobTaskGroup       `json:"job_task_tree" db:"-"`
 }
 
 type JobTask struct {
@@ -25,13 +31,26 @@ type JobTask struct {
 	SchedulerName string              `json:"scheduler_name" db:"scheduler_name"`
 	JobId         string              `json:"job_id" db:"job_id"`
 	Status        constants.JobStatus `json:"status" db:"task_status"`
+	TaskId        string              `json:"task_id" db:"task_id"` // path of task in branch, such as branch/pipeline/task#2
 	TaskName      string              `json:"name" db:"task_name"`
 	TaskType      string              `json:"type" db:"task_type"`
 	ExecutionName string              `json:"execution_name" db:"execution_name"`
+	Attempt       int                 `json:"attempt" db:"attempt"`
 	StartDateTime time.Time           `json:"start_datetime" db:"start_datetime"`
 	EndDatetime   *time.Time          `json:"end_datetime" db:"end_datetime"`
 	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time           `json:"updated_at" db:"updated_at"`
 	TaskException string              `json:"exception" db:"exception"`
 	StackTrace    string              `json:"stacktrace" db:"stacktrace"`
+	TaskValue     string              `json:"-" db:"task_value"`
+	Value         interface{}         `json:"task_value" db:"-"`
+	TaskGroup     string              `json:"task_group" db:"task_group"`
+	SlaMissed     bool                `json:"sla_missed" db:"sla_missed"`
+}
+
+/* hierarchy of task group on job detail */
+type JobTaskGroup struct {
+	Name   string          `json:"name"`
+	Tasks  []*JobTask      `json:"tasks"`
+	Groups []*JobTaskGroup `json:"groups"`
 }
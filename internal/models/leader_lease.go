@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+/* lease of leader which is renewed by node that hold it, fencing token is increased when leader was changed */
+type LeaderLease struct {
+	TableName    struct{}  `json:"-" db:"leader_leases"`
+	Name         string    `json:"name" db:"name"`
+	NodeId       string    `json:"node_id" db:"node_id"`
+	FencingToken int64     `json:"fencing_token" db:"fencing_token"`
+	ExpiredAt    time.Time `json:"expired_at" db:"expired_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
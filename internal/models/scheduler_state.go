@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+/* state of scheduler which is shared by every replica */
+type SchedulerState struct {
+	TableName     struct{}  `json:"-" db:"scheduler_states"`
+	SchedulerName string    `json:"scheduler_name" db:"scheduler_name"`
+	IsPaused      bool      `json:"is_paused" db:"is_paused"`
+	RejectTrigger bool      `json:"reject_trigger" db:"reject_trigger"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
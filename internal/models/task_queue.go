@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+/* task which was enqueued by node which run job and claimed by worker of any node */
+type TaskQueue struct {
+	TableName     struct{}              `json:"-" db:"task_queues"`
+	Id            int64                 `json:"id" db:"id"`
+	SchedulerName string                `json:"scheduler_name" db:"scheduler_name"`
+	JobId         string                `json:"job_id" db:"job_id"`
+	TaskId        string                `json:"task_id" db:"task_id"`
+	Attempt       int                   `json:"attempt" db:"attempt"`
+	Status        constants.QueueStatus `json:"status" db:"status"`
+	Payload       string                `json:"-" db:"payload"`   // state of job which task need
+	Result        string                `json:"-" db:"result"`    // serialized value of task
+	Parameter     string                `json:"-" db:"parameter"` // serialized parameter after task was finished
+	TaskException string                `json:"exception" db:"exception"`
+	StackTrace    string                `json:"stacktrace" db:"stacktrace"`
+	NodeId        *string               `json:"node_id" db:"node_id"` // worker which claim task
+	ClaimCount    int                   `json:"claim_count" db:"claim_count"`
+	HeartbeatAt   *time.Time            `json:"heartbeat_at" db:"heartbeat_at"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at" db:"updated_at"`
+}
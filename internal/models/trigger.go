@@ -18,6 +18,8 @@ type Trigger struct {
 	TriggerType     constants.TriggerType  `json:"type" db:"type"`
 	IsTrigger       bool                   `json:"is_trigger" db:"is_trigger"`
 	IsActive        bool                   `json:"is_active" db:"is_active"`
+	NodeId          string                 `json:"node_id" db:"node_id"`       // node which claimed trigger by dispatcher
+	ClaimedAt       *time.Time             `json:"claimed_at" db:"claimed_at"` // renewed by dispatcher until job was saved
 	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/gommon/log"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	max_backfill_ticks   = 1000
+	max_cron_tick_window = 5 * 366 * 24 * time.Hour
+)
+
+func (s *SchedulerInstance) parseCronRange(start time.Time, end time.Time) (cron.Schedule, error) {
+	if s.cronExpression == "" {
+		return nil, fmt.Errorf("scheduler %s has not cronjob expression", s.name)
+	}
+	if end.Before(start) {
+		return nil, errors.New("end datetime must be after start datetime")
+	}
+	return cron.ParseStandard(s.cronExpression)
+}
+
+/* return every cron tick between start and end (include start and end), range which has tick more than limit is rejected */
+func (s *SchedulerInstance) GetCronTicks(start time.Time, end time.Time) ([]time.Time, error) {
+	schedule, err := s.parseCronRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticks = make([]time.Time, 0)
+	for tick := schedule.Next(start.Truncate(time.Second).Add(-time.Second)); !tick.After(end); tick = schedule.Next(tick) {
+		if len(ticks) == max_backfill_ticks {
+			return nil, fmt.Errorf("range has cron tick more than %d", max_backfill_ticks)
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks, nil
+}
+
+/* return lastest cron tick between start and end not more than limit and total of older tick which was dropped */
+func (s *SchedulerInstance) GetLastestCronTicks(start time.Time, end time.Time, limit int) ([]time.Time, int, error) {
+	schedule, err := s.parseCronRange(start, end)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ticks = make([]time.Time, 0)
+	var dropped int
+	for tick := schedule.Next(start.Truncate(time.Second).Add(-time.Second)); !tick.After(end); tick = schedule.Next(tick) {
+		ticks = append(ticks, tick)
+		if len(ticks) > limit {
+			ticks = ticks[1:]
+			dropped++
+		}
+	}
+	return ticks, dropped, nil
+}
+
+/* return lastest cron tick which is not after ti, ti is returned when scheduler has not cronjob expression */
+func (s *SchedulerInstance) GetCronTick(ti time.Time) time.Time {
+	if s.cronExpression == "" {
+		return ti
+	}
+	schedule, err := cron.ParseStandard(s.cronExpression)
+	if err != nil {
+		return ti
+	}
+	/* widen window until any tick is found, tick in window is walked to lastest */
+	for window := time.Minute; window <= max_cron_tick_window; window *= 2 {
+		tick := schedule.Next(ti.Add(-window))
+		if tick.After(ti) {
+			continue
+		}
+		for next := schedule.Next(tick); !next.After(ti); next = schedule.Next(next) {
+			tick = next
+		}
+		return tick
+	}
+	return ti
+}
+
+/*
+create trigger of every tick, tick is logical execution datetime of job.
+trigger is claimed by dispatcher and job of each tick is run after job of previous tick was finished, tick which was triggered is skipped by unique index of trigger schedule
+*/
+func (s *SchedulerInstance) Backfill(ctx context.Context, ticks []time.Time) ([]*models.Trigger, error) {
+	var triggers = make([]*models.Trigger, 0)
+	for _, tick := range ticks {
+		uid, _ := uuid.NewV4()
+		trigger := &models.Trigger{
+			SchedulerName:   s.name,
+			ExecuteDatetime: tick,
+			JobId:           uid.String(),
+			Config:          nil,
+			TriggerType:     constants.TRIGGER_TYPE_SCHEDULE,
+			IsTrigger:       false,
+			IsActive:        true,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+		if err := s.dbAdapter.GetRepository().CreateTriggerByJobScheduler(ctx, trigger, nil); err != nil {
+			if err.Error() == constants.ERROR_ALREADY_EXISTS {
+				continue
+			}
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, nil
+}
+
+/* enqueue every cron tick which was missed since last schedule trigger */
+func (s *SchedulerInstance) catchUp(ctx context.Context) error {
+	last, err := s.dbAdapter.GetRepository().GetLastTriggerSchedule(ctx, s.name)
+	if err != nil {
+		return err
+	}
+	/* scheduler has never been run by cronjob */
+	if last == nil {
+		return nil
+	}
+
+	/* scheduler which was stopped for long time catch up only lastest tick */
+	ticks, dropped, err := s.GetLastestCronTicks(last.ExecuteDatetime.Add(time.Second), time.Now(), max_backfill_ticks)
+	if err != nil {
+		return err
+	}
+	if dropped > 0 {
+		log.Warnf("catch up scheduler %s dropped %d cron tick between %s and %s", s.name, dropped,
+			last.ExecuteDatetime.Format(constants.TIME_FORMAT_RFC339), ticks[0].Format(constants.TIME_FORMAT_RFC339))
+	}
+	triggers, err := s.Backfill(ctx, ticks)
+	if err != nil {
+		return err
+	}
+	if len(triggers) > 0 {
+		s.logger.Info("catch up scheduler", map[string]interface{}{
+			"scheduler_name": s.name,
+			"total_job":      len(triggers),
+		})
+	}
+	return nil
+}
+
+func (s *SchedulerInstance) startCatchUp() {
+	if !s.config.CatchUp || s.cronExpression == "" || s.IsPaused() {
+		return
+	}
+	go func() {
+		if isLeader, err := s.isLeader(context.Background()); err != nil || !isLeader {
+			return
+		}
+		if err := s.catchUp(context.Background()); err != nil {
+			log.Errorf("failed to catch up scheduler %s with error: %s", s.name, err.Error())
+		}
+	}()
+}
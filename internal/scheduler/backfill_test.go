@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+)
+
+func TestGetCronTicks(t *testing.T) {
+	s := NewScheduler("0 * * * *", "hourly", "", NewDefaultSchedulerConfig())
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		total int
+		first time.Time
+		err   string
+	}{
+		{name: "include start and end", start: base, end: base.Add(3 * time.Hour), total: 4, first: base},
+		{name: "start between tick", start: base.Add(time.Minute), end: base.Add(3 * time.Hour), total: 3, first: base.Add(time.Hour)},
+		{name: "no tick", start: base.Add(time.Minute), end: base.Add(59 * time.Minute), total: 0},
+		{name: "end before start", start: base.Add(time.Hour), end: base, err: "must be after"},
+		{name: "too many tick", start: base, end: base.Add(1001 * time.Hour), err: "more than 1000"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ticks, err := s.GetCronTicks(tc.start, tc.end)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ticks) != tc.total {
+				t.Fatalf("expected %d ticks, got %d", tc.total, len(ticks))
+			}
+			if tc.total > 0 && !ticks[0].Equal(tc.first) {
+				t.Fatalf("expected first tick %s, got %s", tc.first, ticks[0])
+			}
+		})
+	}
+
+	if _, err := NewScheduler("", "manual", "", NewDefaultSchedulerConfig()).GetCronTicks(base, base); err == nil {
+		t.Fatal("expected error of scheduler without cronjob expression")
+	}
+}
+
+func TestGetCronTick(t *testing.T) {
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		cron     string
+		ti       time.Time
+		expected time.Time
+	}{
+		{"0 * * * *", base, base},
+		{"0 * * * *", base.Add(59*time.Minute + 30*time.Second), base},
+		{"*/5 * * * *", base.Add(7 * time.Minute), base.Add(5 * time.Minute)},
+		{"0 0 1 * *", base.Add(20 * 24 * time.Hour), base},
+		{"0 0 1 1 *", base.Add(200 * 24 * time.Hour), base},
+		{"", base.Add(time.Minute), base.Add(time.Minute)},
+	}
+	for _, tc := range cases {
+		s := NewScheduler(tc.cron, "tick", "", NewDefaultSchedulerConfig())
+		if actual := s.GetCronTick(tc.ti); !actual.Equal(tc.expected) {
+			t.Errorf("cron %q at %s: expected %s, got %s", tc.cron, tc.ti, tc.expected, actual)
+		}
+	}
+}
+
+func TestGetLastestCronTicks(t *testing.T) {
+	s := NewScheduler("0 * * * *", "hourly", "", NewDefaultSchedulerConfig())
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		name    string
+		end     time.Time
+		total   int
+		dropped int
+	}{
+		{name: "under limit", end: base.Add(2 * time.Hour), total: 3},
+		{name: "over limit", end: base.Add(9 * time.Hour), total: 5, dropped: 5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ticks, dropped, err := s.GetLastestCronTicks(base, tc.end, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ticks) != tc.total || dropped != tc.dropped {
+				t.Fatalf("expected %d ticks and %d dropped, got %d and %d", tc.total, tc.dropped, len(ticks), dropped)
+			}
+			if !ticks[len(ticks)-1].Equal(tc.end) {
+				t.Fatalf("expected lastest tick %s, got %s", tc.end, ticks[len(ticks)-1])
+			}
+		})
+	}
+}
+
+/* scheduler which missed tick more than limit catch up only lastest tick instead of nothing */
+func TestCatchUpLongDowntime(t *testing.T) {
+	repository := newFakeRepository()
+	s := newTestScheduler(t, "a")
+	s.cronExpression = "* * * * *"
+	s.SetAdapter(fakeAdapter{repository: repository})
+	last := time.Now().Truncate(time.Minute).Add(-2000 * time.Minute)
+	repository.triggers = append(repository.triggers, models.Trigger{SchedulerName: "a", ExecuteDatetime: last, TriggerType: constants.TRIGGER_TYPE_SCHEDULE})
+
+	if err := s.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	triggers := repository.triggers[1:]
+	if len(triggers) != max_backfill_ticks {
+		t.Fatalf("expected %d triggers, got %d", max_backfill_ticks, len(triggers))
+	}
+	if lastest := triggers[len(triggers)-1].ExecuteDatetime; time.Since(lastest) > time.Minute {
+		t.Fatalf("expected lastest tick was caught up, got %s", lastest)
+	}
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+/* pipeline which was not selected is saved as skipped, include task in pipeline of nested branch */
+func (jr *jobRunner) skipPipeline(tasks []task.Execution) {
+	for _, taskExecution := range tasks {
+		jr.skipTask(taskExecution)
+		if branch, ok := taskExecution.(*task.TaskBranch); ok {
+			for _, pipeline := range branch.GetPipelines() {
+				jr.skipPipeline(pipeline.GetTasks())
+			}
+		}
+	}
+}
+
+/*
+run selected pipelines concurrently and join by join mode of branch.
+on first success mode failure of pipeline does not fail job, pending task of another pipeline
+is skipped when first pipeline was success and branch is failed when every pipeline was failed
+*/
+func (jr *jobRunner) runBranch(branch *task.TaskBranch, selected task.TaskBranchPipeLines) bool {
+	for _, pipeline := range branch.GetPipelines() {
+		if _, ok := selected.Get(pipeline.GetName()); !ok {
+			jr.skipPipeline(pipeline.GetTasks())
+		}
+	}
+	if len(selected) == 0 {
+		return true
+	}
+
+	var isFirstSuccess = branch.GetJoinMode() == constants.BRANCH_JOIN_MODE_FIRST_SUCCESS
+	var isCancelled func() bool
+	var cancelled int32
+	if isFirstSuccess {
+		jr.mutex.Lock()
+		for _, pipeline := range selected {
+			jr.tolerated[branch.GetId()+"/"+pipeline.GetName()+"/"] = true
+		}
+		jr.mutex.Unlock()
+		isCancelled = func() bool {
+			return atomic.LoadInt32(&cancelled) == 1
+		}
+	}
+
+	var wg sync.WaitGroup
+	var results = make([]bool, len(selected))
+	for index, pipeline := range selected {
+		wg.Add(1)
+		go func(index int, pipeline task.TaskBranchPipeLine) {
+			defer wg.Done()
+			results[index] = jr.runGraph(newLinearTaskGraph(pipeline.GetTasks()), nil, isCancelled)
+			if results[index] && isFirstSuccess {
+				atomic.StoreInt32(&cancelled, 1)
+			}
+		}(index, pipeline)
+	}
+	wg.Wait()
+
+	for _, isSuccess := range results {
+		if isSuccess && isFirstSuccess {
+			return true
+		}
+		if !isSuccess && !isFirstSuccess {
+			return false
+		}
+	}
+	if isFirstSuccess {
+		jr.fail(branch, constants.JOB_STATUS_FAILED, fmt.Errorf("every pipeline of branch %s was failed", branch.GetId()))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+	"github.com/Blackmocca/go-lightweight-scheduler/service/v1/schedule"
+)
+
+/* repository keep saved job and task in memory, method which is not used by runner is not implemented */
+type fakeRepository struct {
+	schedule.Repository
+	mutex    sync.Mutex
+	tasks    map[string]models.JobTask
+	history  []models.JobTask // every saved task by order of save
+	job      models.Job       // lastest saved job
+	jobs     map[string]models.Job
+	states   map[string]models.SchedulerState
+	triggers []models.Trigger
+	queue    map[int64]*models.TaskQueue
+	leases   map[string]models.LeaderLease
+	released []string // job id of trigger which was handed back
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		tasks:  map[string]models.JobTask{},
+		jobs:   map[string]models.Job{},
+		states: map[string]models.SchedulerState{},
+		leases: map[string]models.LeaderLease{},
+	}
+}
+
+func (f *fakeRepository) UpsertJobTask(ctx context.Context, jobTask *models.JobTask) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.tasks[jobTask.TaskId] = *jobTask
+	f.history = append(f.history, *jobTask)
+	return nil
+}
+
+func (f *fakeRepository) UpsertJob(ctx context.Context, job *models.Job) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.job = *job
+	f.jobs[job.JobId] = *job
+	return nil
+}
+
+func (f *fakeRepository) GetOneJob(ctx context.Context, jobId string) (*models.Job, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	job, ok := f.jobs[jobId]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (f *fakeRepository) ClaimRerunJob(ctx context.Context, jobId string, nodeId string, status constants.JobStatus) (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	job, ok := f.jobs[jobId]
+	if !ok || job.Status != status {
+		return false, nil
+	}
+	job.Status = constants.JOB_STATUS_WAITING
+	job.NodeId = nodeId
+	f.jobs[jobId] = job
+	return true, nil
+}
+
+func (f *fakeRepository) UpsertTrigger(ctx context.Context, trigger *models.Trigger) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.triggers = append(f.triggers, *trigger)
+	return nil
+}
+
+func (f *fakeRepository) GetSchedulerState(ctx context.Context, schedulerName string) (*models.SchedulerState, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	state, ok := f.states[schedulerName]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (f *fakeRepository) UpsertSchedulerState(ctx context.Context, state *models.SchedulerState) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.states[state.SchedulerName] = *state
+	return nil
+}
+
+/* schedule trigger is unique by scheduler and execute datetime */
+func (f *fakeRepository) CreateTriggerByJobScheduler(ctx context.Context, trigger *models.Trigger, lease *models.LeaderLease) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, item := range f.triggers {
+		if item.SchedulerName == trigger.SchedulerName && item.ExecuteDatetime.Equal(trigger.ExecuteDatetime) {
+			return errors.New(constants.ERROR_ALREADY_EXISTS)
+		}
+	}
+	f.triggers = append(f.triggers, *trigger)
+	return nil
+}
+
+/* lastest schedule trigger which was created by cronjob or backfill */
+func (f *fakeRepository) GetLastTriggerSchedule(ctx context.Context, schedulerName string) (*models.Trigger, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var last *models.Trigger
+	for index, trigger := range f.triggers {
+		if trigger.SchedulerName == schedulerName && trigger.TriggerType == constants.TRIGGER_TYPE_SCHEDULE && (last == nil || trigger.ExecuteDatetime.After(last.ExecuteDatetime)) {
+			last = &f.triggers[index]
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	trigger := *last
+	return &trigger, nil
+}
+
+func (f *fakeRepository) ExecuteFutureJob(ctx context.Context, trigger *models.Trigger) (*models.Trigger, error) {
+	return trigger, nil
+}
+
+type fakeAdapter struct {
+	repository *fakeRepository
+}
+
+func (f fakeAdapter) GetClient() interface{}                        { return nil }
+func (f fakeAdapter) SetClient(ctx context.Context, db interface{}) {}
+func (f fakeAdapter) GetConnectionURI() string                      { return "" }
+func (f fakeAdapter) GetDatabaseType() constants.AdapterDatabaseConnectionType {
+	return constants.ADAPTER_DATABASE_POSTGRES
+}
+func (f fakeAdapter) GetRepository() schedule.Repository { return f.repository }
+func (f fakeAdapter) IsConnect(ctx context.Context) bool { return true }
+func (f fakeAdapter) Close(ctx context.Context) error    { return nil }
+
+func newResultTask(name string, err error) task.Execution {
+	return task.NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		return name, err
+	}))
+}
+
+func newResultTaskFunc(name string, fn func()) task.Execution {
+	return task.NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		fn()
+		return name, nil
+	}))
+}
+
+func newTestTrigger(schedulerName string, jobId string) *models.Trigger {
+	return &models.Trigger{SchedulerName: schedulerName, JobId: jobId, IsActive: true, TriggerType: constants.TRIGGER_TYPE_EXTERNAL}
+}
+
+/* run job immediately and return repository which keep lastest status of job and every task */
+func runTestJob(t *testing.T, job *JobInstance) *fakeRepository {
+	return runTestJobWithConfig(t, job, NewDefaultSchedulerConfig())
+}
+
+func runTestJobWithConfig(t *testing.T, job *JobInstance, config SchedulerConfig) *fakeRepository {
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	s.SetAdapter(fakeAdapter{repository: repository})
+	s.Run(newTestTrigger(s.name, "00000000-0000-0000-0000-000000000001"))
+	return repository
+}
+
+func TestRunBranchJoinMode(t *testing.T) {
+	boom := errors.New("boom")
+	cases := []struct {
+		name     string
+		mode     constants.BranchJoinMode
+		errA     error
+		errB     error
+		job      constants.JobStatus
+		statuses map[string]constants.JobStatus
+	}{
+		{
+			name: "all success", mode: constants.BRANCH_JOIN_MODE_ALL, job: constants.JOB_STATUS_SUCCESS,
+			statuses: map[string]constants.JobStatus{"branch": constants.JOB_STATUS_SUCCESS, "branch/a/a1": constants.JOB_STATUS_SUCCESS, "branch/b/b1": constants.JOB_STATUS_SUCCESS, "branch/c/c1": constants.JOB_STATUS_SKIPPED, "after": constants.JOB_STATUS_SUCCESS},
+		},
+		{
+			name: "all with failed pipeline", mode: constants.BRANCH_JOIN_MODE_ALL, errA: boom, job: constants.JOB_STATUS_FAILED,
+			statuses: map[string]constants.JobStatus{"branch/a/a1": constants.JOB_STATUS_FAILED, "branch/b/b1": constants.JOB_STATUS_SUCCESS, "after": constants.JOB_STATUS_SKIPPED},
+		},
+		{
+			name: "first success with failed pipeline", mode: constants.BRANCH_JOIN_MODE_FIRST_SUCCESS, errA: boom, job: constants.JOB_STATUS_SUCCESS,
+			statuses: map[string]constants.JobStatus{"branch": constants.JOB_STATUS_SUCCESS, "branch/a/a1": constants.JOB_STATUS_FAILED, "branch/b/b1": constants.JOB_STATUS_SUCCESS, "after": constants.JOB_STATUS_SUCCESS},
+		},
+		{
+			name: "first success with every pipeline failed", mode: constants.BRANCH_JOIN_MODE_FIRST_SUCCESS, errA: boom, errB: boom, job: constants.JOB_STATUS_FAILED,
+			statuses: map[string]constants.JobStatus{"branch/a/a1": constants.JOB_STATUS_FAILED, "branch/b/b1": constants.JOB_STATUS_FAILED, "after": constants.JOB_STATUS_SKIPPED},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			/* pipeline b is finished after a1 was started, a1 is not skipped by first success */
+			started := make(chan struct{})
+			a1 := task.NewTask("a1", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				close(started)
+				return "a1", tc.errA
+			}))
+			b1 := task.NewTask("b1", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				<-started
+				return "b1", tc.errB
+			}))
+			branch := task.NewTaskBranch("branch", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				return []interface{}{"a", "b"}, nil
+			}), task.NewTaskBranchPipeline(map[string][]task.Execution{
+				"a": {a1},
+				"b": {b1},
+				"c": {newResultTask("c1", nil)},
+			}))
+			branch.(*task.TaskBranch).SetJoinMode(tc.mode)
+			after := newResultTask("after", nil)
+			job := NewJob(nil)
+			job.AddTask(branch, after)
+			job.SetDownstream(branch, after)
+
+			repository := runTestJob(t, job)
+			if repository.job.Status != tc.job {
+				t.Errorf("expected job %s, got %s", tc.job, repository.job.Status)
+			}
+			for taskId, status := range tc.statuses {
+				if jobTask := repository.tasks[taskId]; jobTask.Status != status {
+					t.Errorf("expected task %s %s, got %s", taskId, status, jobTask.Status)
+				}
+			}
+		})
+	}
+}
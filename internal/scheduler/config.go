@@ -10,6 +10,7 @@ import (
 
 const (
 	default_max_active_concurrent = 32
+	default_max_value_size        = 64 * 1024
 )
 
 var (
@@ -22,8 +23,14 @@ type SchedulerConfig struct {
 	MaxActiveConcurrent int
 	RetryTimes          int
 	RetryDelay          time.Duration
-	JobTimeout          time.Duration
+	JobTimeout          time.Duration // deadline of whole job, 0 is no deadline
+	TaskTimeout         time.Duration // deadline of each task, 0 is no deadline
 	JobMode             constants.JobMode
+	CatchUp             bool                    // run every cron tick which was missed since last schedule trigger on start
+	ExecutionMode       constants.ExecutionMode // queue mode send task to worker of any node, sensor and instance of mapped task is called locally
+	OrphanPolicy        constants.OrphanPolicy  // running job which node stopped heartbeat is failed or run again
+	Serializer          Serializer              // serializer of task value and parameter which are saved in database
+	MaxValueSize        int                     // max size of serialized value in bytes, value which is larger will not be saved. 0 is no limit
 	OnSuccess           func(ctx context.Context) error
 	OnError             func(ctx context.Context) error
 }
@@ -34,6 +41,11 @@ func NewDefaultSchedulerConfig() SchedulerConfig {
 		RetryTimes:          0,
 		RetryDelay:          0,
 		JobMode:             constants.JOB_MODE_CONCURRENT,
+		CatchUp:             false,
+		ExecutionMode:       constants.EXECUTION_MODE_LOCAL,
+		OrphanPolicy:        constants.ORPHAN_POLICY_FAIL,
+		Serializer:          NewJsonSerializer(),
+		MaxValueSize:        default_max_value_size,
 		OnSuccess:           nil,
 		OnError:             nil,
 	}
@@ -41,23 +53,56 @@ func NewDefaultSchedulerConfig() SchedulerConfig {
 
 func (s SchedulerConfig) MarshalJSON() ([]byte, error) {
 	type ptr struct {
-		MaxActiveConcurrent int  `json:"max_active_concurrent"`
-		RetryTimes          int  `json:"retry_times"`
-		RetryDelay          int  `json:"retry_delay"`
-		JobTimeout          int  `json:"job_timeout"`
-		JobMode             int8 `json:"job_mode"`
-		OnSuccess           bool `json:"is_handle_on_success"`
-		OnError             bool `json:"is_handle_on_error"`
+		MaxActiveConcurrent int    `json:"max_active_concurrent"`
+		RetryTimes          int    `json:"retry_times"`
+		RetryDelay          int    `json:"retry_delay"`
+		JobTimeout          int    `json:"job_timeout"`
+		TaskTimeout         int    `json:"task_timeout"`
+		JobMode             int8   `json:"job_mode"`
+		CatchUp             bool   `json:"catch_up"`
+		ExecutionMode       string `json:"execution_mode"`
+		OrphanPolicy        string `json:"orphan_policy"`
+		Serializer          string `json:"serializer"`
+		MaxValueSize        int    `json:"max_value_size"`
+		OnSuccess           bool   `json:"is_handle_on_success"`
+		OnError             bool   `json:"is_handle_on_error"`
 	}
 	var sh = ptr{
 		MaxActiveConcurrent: s.MaxActiveConcurrent,
 		RetryTimes:          s.RetryTimes,
 		RetryDelay:          int(s.RetryDelay),
 		JobTimeout:          int(s.JobTimeout),
+		TaskTimeout:         int(s.TaskTimeout),
 		JobMode:             int8(s.JobMode),
+		CatchUp:             s.CatchUp,
+		ExecutionMode:       string(s.getExecutionMode()),
+		OrphanPolicy:        string(s.getOrphanPolicy()),
+		Serializer:          s.getSerializer().GetName(),
+		MaxValueSize:        s.MaxValueSize,
 		OnSuccess:           s.OnSuccess != nil,
 		OnError:             s.OnError != nil,
 	}
 
 	return json.Marshal(sh)
 }
+
+func (s SchedulerConfig) getSerializer() Serializer {
+	if s.Serializer == nil {
+		return NewJsonSerializer()
+	}
+	return s.Serializer
+}
+
+func (s SchedulerConfig) getExecutionMode() constants.ExecutionMode {
+	if s.ExecutionMode == "" {
+		return constants.EXECUTION_MODE_LOCAL
+	}
+	return s.ExecutionMode
+}
+
+func (s SchedulerConfig) getOrphanPolicy() constants.OrphanPolicy {
+	if s.OrphanPolicy == "" {
+		return constants.ORPHAN_POLICY_FAIL
+	}
+	return s.OrphanPolicy
+}
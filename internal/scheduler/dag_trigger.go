@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/gofrs/uuid"
+)
+
+/* trigger another scheduler in registry, job is run on background and job id is returned immediately */
+func (jr *jobRunner) TriggerDag(ctx context.Context, schedulerName string, config map[string]interface{}) (string, error) {
+	if jr.registry == nil {
+		return "", errors.New("scheduler registry was not found")
+	}
+	schedulerInstance := jr.registry.Get(schedulerName)
+	if schedulerInstance == nil {
+		return "", fmt.Errorf("scheduler name '%s' not found", schedulerName)
+	}
+	if err := schedulerInstance.LoadState(ctx); err != nil {
+		return "", err
+	}
+	if state := schedulerInstance.GetState(); state.IsPaused && state.RejectTrigger {
+		return "", fmt.Errorf("scheduler name '%s' was paused", schedulerName)
+	}
+
+	uid, _ := uuid.NewV4()
+	trigger := &models.Trigger{
+		SchedulerName:   schedulerName,
+		JobId:           uid.String(),
+		ExecuteDatetime: time.Now(),
+		Config:          config,
+		IsActive:        true,
+		IsTrigger:       false,
+		TriggerType:     constants.TRIGGER_TYPE_EXTERNAL,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if err := jr.dbAdapter.GetRepository().UpsertTrigger(ctx, trigger); err != nil {
+		return "", err
+	}
+	go schedulerInstance.Run(trigger)
+	return uid.String(), nil
+}
+
+/* return nil when job was not started */
+func (jr *jobRunner) GetJob(ctx context.Context, jobId string) (*models.Job, error) {
+	return jr.dbAdapter.GetRepository().GetOneJob(ctx, jobId)
+}
+
+func (jr *jobRunner) GetLastestJob(ctx context.Context, schedulerName string, since time.Time, statuses ...constants.JobStatus) (*models.Job, error) {
+	return jr.dbAdapter.GetRepository().GetLastestJobByScheduler(ctx, schedulerName, since, statuses...)
+}
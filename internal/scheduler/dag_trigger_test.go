@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func TestTriggerDag(t *testing.T) {
+	cases := []struct {
+		name      string
+		paused    bool
+		reject    bool
+		status    constants.JobStatus
+		exception string
+	}{
+		{name: "wait for finish", status: constants.JOB_STATUS_SUCCESS},
+		{name: "paused without reject", paused: true, status: constants.JOB_STATUS_SUCCESS},
+		{name: "paused with reject", paused: true, reject: true, status: constants.JOB_STATUS_FAILED, exception: "scheduler name 'target' was paused"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := newFakeRepository()
+			registry := NewRegistry()
+			if err := registry.Start(fakeAdapter{repository: repository}); err != nil {
+				t.Fatal(err)
+			}
+			defer registry.Stop()
+
+			target := newTestScheduler(t, "target")
+			job := NewJob(nil)
+			job.AddTask(task.NewTask("trigger", executor.NewTriggerDagExecutor(executor.TriggerDagExecutorConfig{
+				SchedulerName: "target",
+				Config:        map[string]interface{}{"key": "value"},
+				WaitForFinish: true,
+				PokeInterval:  5 * time.Millisecond,
+			})))
+			source := NewScheduler("", "source", "", NewDefaultSchedulerConfig())
+			if err := source.RegisterJob(job); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range []*SchedulerInstance{target, source} {
+				if err := registry.Add(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.paused {
+				if err := target.Pause(context.Background(), tc.reject); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			source.Run(newTestTrigger("source", "00000000-0000-0000-0000-000000000001"))
+			sourceJob, _ := repository.GetOneJob(context.Background(), "00000000-0000-0000-0000-000000000001")
+			if sourceJob == nil || sourceJob.Status != tc.status {
+				t.Fatalf("expected source job %s, got %v", tc.status, sourceJob)
+			}
+			if tc.exception != "" {
+				if exception := repository.tasks["trigger"].TaskException; !strings.Contains(exception, tc.exception) {
+					t.Fatalf("expected exception %q, got %q", tc.exception, exception)
+				}
+				if len(repository.triggers) != 0 {
+					t.Fatal("trigger of paused scheduler was created")
+				}
+				return
+			}
+			if len(repository.triggers) != 1 {
+				t.Fatalf("expected 1 trigger, got %d", len(repository.triggers))
+			}
+			trigger := repository.triggers[0]
+			if trigger.SchedulerName != "target" || trigger.TriggerType != constants.TRIGGER_TYPE_EXTERNAL || trigger.Config["key"] != "value" {
+				t.Fatalf("unexpected trigger %+v", trigger)
+			}
+			if targetJob, _ := repository.GetOneJob(context.Background(), trigger.JobId); targetJob == nil || targetJob.Status != constants.JOB_STATUS_SUCCESS {
+				t.Fatalf("expected target job %s, got %v", constants.JOB_STATUS_SUCCESS, targetJob)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	default_trigger_poll_interval = time.Second
+	default_trigger_batch_size    = 100
+	default_trigger_claim_timeout = time.Minute
+)
+
+/*
+dispatcher poll trigger which execute datetime was due from database and run it on this node,
+trigger is not kept in memory until it was due so trigger which was deactivated is never run.
+trigger of one scheduler is run one after another by order of execute datetime, so trigger of backfill is run by order of tick.
+claim is renewed until job was saved, claim of node which was stopped before job was saved is released by any node
+*/
+type Dispatcher struct {
+	nodeId       string
+	registry     *Registry
+	dbAdapter    connection.DatabaseAdapterConnection
+	pollInterval time.Duration
+	batchSize    int           // max trigger which was claimed but job was not finished
+	claimTimeout time.Duration // claim which was not renewed longer than timeout is released
+	mutex        *sync.Mutex
+	running      map[string]bool              // job id of trigger which was claimed by this node
+	queues       map[string][]*models.Trigger // claimed trigger of scheduler which wait for previous trigger was run
+	stop         chan struct{}
+	stopped      chan struct{}
+}
+
+func NewDispatcher(nodeId string, registry *Registry, pollInterval time.Duration, batchSize int, claimTimeout time.Duration) *Dispatcher {
+	if pollInterval <= 0 {
+		pollInterval = default_trigger_poll_interval
+	}
+	if batchSize <= 0 {
+		batchSize = default_trigger_batch_size
+	}
+	if claimTimeout <= 0 {
+		claimTimeout = default_trigger_claim_timeout
+	}
+	return &Dispatcher{
+		nodeId:       nodeId,
+		registry:     registry,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		claimTimeout: claimTimeout,
+		mutex:        new(sync.Mutex),
+		running:      make(map[string]bool),
+		queues:       make(map[string][]*models.Trigger),
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+}
+
+/* trigger which was due during node was stopped is dispatched on first poll */
+func (d *Dispatcher) Start(dbAdapter connection.DatabaseAdapterConnection) {
+	d.dbAdapter = dbAdapter
+	go d.loop()
+}
+
+/* stop claim trigger, job which was dispatched is waited by shutdown of registry */
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	<-d.stopped
+}
+
+func (d *Dispatcher) loop() {
+	defer close(d.stopped)
+	/* due time is compared with current clock on every poll, clock which was changed take effect on next poll */
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	claimTicker := time.NewTicker(d.claimTimeout / 3)
+	defer claimTicker.Stop()
+	d.dispatch()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.dispatch()
+		case <-claimTicker.C:
+			d.renewClaims()
+		}
+	}
+}
+
+func (d *Dispatcher) renewClaims() {
+	d.mutex.Lock()
+	var jobIds = make([]string, 0, len(d.running))
+	for jobId := range d.running {
+		jobIds = append(jobIds, jobId)
+	}
+	d.mutex.Unlock()
+
+	repository := d.dbAdapter.GetRepository()
+	if err := repository.HeartbeatTriggers(context.Background(), d.nodeId, jobIds); err != nil {
+		log.Errorf("failed to renew claim of trigger with error: %s", err.Error())
+	}
+	count, err := repository.ReleaseStaleTriggers(context.Background(), d.claimTimeout)
+	if err != nil {
+		log.Errorf("failed to release stale claim of trigger with error: %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("release %d trigger which claim was stale", count)
+	}
+}
+
+/* scheduler which was registered on this node */
+func (d *Dispatcher) getSchedulerNames() []string {
+	var names = make([]string, 0)
+	for _, schedulerInstance := range d.registry.List() {
+		names = append(names, schedulerInstance.GetName())
+	}
+	return names
+}
+
+func (d *Dispatcher) dispatch() {
+	d.mutex.Lock()
+	free := d.batchSize - len(d.running)
+	d.mutex.Unlock()
+	if free <= 0 {
+		return
+	}
+
+	triggers, err := d.dbAdapter.GetRepository().ClaimDueTriggers(context.Background(), d.nodeId, d.getSchedulerNames(), time.Now(), free)
+	if err != nil {
+		log.Errorf("failed to claim due trigger with error: %s", err.Error())
+		return
+	}
+	/* order of returning rows is not guaranteed */
+	sort.SliceStable(triggers, func(i, j int) bool { return triggers[i].ExecuteDatetime.Before(triggers[j].ExecuteDatetime) })
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, trigger := range triggers {
+		d.running[trigger.JobId] = true
+		queue, ok := d.queues[trigger.SchedulerName]
+		/* trigger which was released and claimed again could be earlier than trigger on queue */
+		queue = append(queue, trigger)
+		sort.SliceStable(queue, func(i, j int) bool { return queue[i].ExecuteDatetime.Before(queue[j].ExecuteDatetime) })
+		d.queues[trigger.SchedulerName] = queue
+		if !ok {
+			go d.runQueue(trigger.SchedulerName)
+		}
+	}
+}
+
+/* run trigger on queue of scheduler until queue was empty */
+func (d *Dispatcher) runQueue(schedulerName string) {
+	for {
+		d.mutex.Lock()
+		queue := d.queues[schedulerName]
+		if len(queue) == 0 {
+			delete(d.queues, schedulerName)
+			d.mutex.Unlock()
+			return
+		}
+		trigger := queue[0]
+		d.queues[schedulerName] = queue[1:]
+		d.mutex.Unlock()
+		d.run(trigger)
+	}
+}
+
+func (d *Dispatcher) run(trigger *models.Trigger) {
+	defer func() {
+		d.mutex.Lock()
+		delete(d.running, trigger.JobId)
+		d.mutex.Unlock()
+	}()
+
+	/* scheduler was removed after trigger was claimed */
+	schedulerInstance := d.registry.Get(trigger.SchedulerName)
+	if schedulerInstance == nil || schedulerInstance.jobInstance == nil {
+		if err := d.dbAdapter.GetRepository().ReleaseTrigger(context.Background(), trigger.JobId); err != nil {
+			log.Errorf("failed to release trigger of job %s with error: %s", trigger.JobId, err.Error())
+		}
+		return
+	}
+	schedulerInstance.dispatch(trigger)
+}
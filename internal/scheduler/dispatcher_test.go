@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+/* due trigger is claimed by order of execute datetime, order of returned trigger is not guaranteed like database */
+func (f *fakeRepository) ClaimDueTriggers(ctx context.Context, nodeId string, schedulerNames []string, executeDatetime time.Time, limit int) ([]*models.Trigger, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var indexes = make([]int, 0)
+	for index, trigger := range f.triggers {
+		if !trigger.ExecuteDatetime.After(executeDatetime) && !trigger.IsTrigger && trigger.IsActive && strings.Contains(","+strings.Join(schedulerNames, ",")+",", ","+trigger.SchedulerName+",") {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return f.triggers[indexes[i]].ExecuteDatetime.Before(f.triggers[indexes[j]].ExecuteDatetime)
+	})
+	var triggers = make([]*models.Trigger, 0)
+	for _, index := range indexes {
+		if len(triggers) >= limit {
+			break
+		}
+		ti := time.Now()
+		trigger := &f.triggers[index]
+		trigger.IsTrigger, trigger.NodeId, trigger.ClaimedAt = true, nodeId, &ti
+		copied := *trigger
+		triggers = append([]*models.Trigger{&copied}, triggers...)
+	}
+	return triggers, nil
+}
+
+func (f *fakeRepository) HeartbeatTriggers(ctx context.Context, nodeId string, jobIds []string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for index, trigger := range f.triggers {
+		for _, jobId := range jobIds {
+			if trigger.JobId == jobId && trigger.NodeId == nodeId && trigger.ClaimedAt != nil {
+				ti := time.Now()
+				f.triggers[index].ClaimedAt = &ti
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) ReleaseStaleTriggers(ctx context.Context, timeout time.Duration) (int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var count int64
+	for index, trigger := range f.triggers {
+		if _, ok := f.jobs[trigger.JobId]; ok {
+			continue
+		}
+		if trigger.IsTrigger && trigger.IsActive && trigger.ClaimedAt != nil && time.Since(*trigger.ClaimedAt) > timeout {
+			f.triggers[index].IsTrigger, f.triggers[index].NodeId, f.triggers[index].ClaimedAt = false, "", nil
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeRepository) getTrigger(jobId string) models.Trigger {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, trigger := range f.triggers {
+		if trigger.JobId == jobId {
+			return trigger
+		}
+	}
+	return models.Trigger{}
+}
+
+func newDueTrigger(schedulerName string, jobId string, executeDatetime time.Time) *models.Trigger {
+	trigger := newTestTrigger(schedulerName, jobId)
+	trigger.ExecuteDatetime = executeDatetime
+	return trigger
+}
+
+/* registry with started scheduler which task is run by fn, dispatcher is not started */
+func newDispatcherTest(t *testing.T, fn func(ctx context.Context)) (*Dispatcher, *SchedulerInstance, *fakeRepository) {
+	job := NewJob(nil)
+	job.AddTask(task.NewTask("a", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		fn(ctx)
+		return "a", nil
+	})))
+	config := NewDefaultSchedulerConfig()
+	config.MaxActiveConcurrent = 4
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	if err := registry.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	adapter := fakeAdapter{repository: repository}
+	if err := registry.Start(adapter); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(registry.Stop)
+	dispatcher := NewDispatcher("node", registry, 5*time.Millisecond, 0, time.Minute)
+	dispatcher.dbAdapter = adapter
+	return dispatcher, s, repository
+}
+
+func TestDispatcherClaim(t *testing.T) {
+	dispatcher, s, repository := newDispatcherTest(t, func(ctx context.Context) {})
+	now := time.Now()
+	for _, trigger := range []*models.Trigger{
+		newDueTrigger(s.name, "due", now.Add(-time.Minute)),
+		newDueTrigger(s.name, "future", now.Add(time.Hour)),
+		newDueTrigger("other", "other", now.Add(-time.Minute)),
+	} {
+		repository.UpsertTrigger(context.Background(), trigger)
+	}
+	inactive := newDueTrigger(s.name, "inactive", now.Add(-time.Minute))
+	inactive.IsActive = false
+	repository.UpsertTrigger(context.Background(), inactive)
+
+	dispatcher.Start(fakeAdapter{repository: repository})
+	defer dispatcher.Stop()
+	waitJobStatus(t, repository, "due", constants.JOB_STATUS_SUCCESS)
+	if trigger := repository.getTrigger("due"); !trigger.IsTrigger || trigger.NodeId != "node" || trigger.ClaimedAt == nil {
+		t.Fatalf("expected due trigger was claimed by node, got %+v", trigger)
+	}
+	/* trigger which was not due, inactive or of scheduler which was not registered on node is not claimed */
+	for _, jobId := range []string{"future", "other", "inactive"} {
+		if trigger := repository.getTrigger(jobId); trigger.IsTrigger {
+			t.Fatalf("expected trigger %s was not claimed", jobId)
+		}
+		if job, _ := repository.GetOneJob(context.Background(), jobId); job != nil {
+			t.Fatalf("expected job %s was not run, got %s", jobId, job.Status)
+		}
+	}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	var mutex sync.Mutex
+	var order = make([]string, 0)
+	var active, maxActive int
+	first, claimed := make(chan struct{}), make(chan struct{})
+	dispatcher, s, repository := newDispatcherTest(t, func(ctx context.Context) {
+		jobId := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(interface{ GetId() string }).GetId()
+		mutex.Lock()
+		order = append(order, jobId)
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mutex.Unlock()
+		if jobId == "2" {
+			close(first)
+			<-claimed
+		}
+		time.Sleep(5 * time.Millisecond)
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+	})
+	now := time.Now()
+	for _, tick := range []int{4, 3, 2} {
+		repository.UpsertTrigger(context.Background(), newDueTrigger(s.name, fmt.Sprint(tick), now.Add(time.Duration(tick-10)*time.Minute)))
+	}
+
+	dispatcher.Start(fakeAdapter{repository: repository})
+	defer dispatcher.Stop()
+	/* trigger which was released and claimed again on next poll is run before later tick */
+	<-first
+	repository.UpsertTrigger(context.Background(), newDueTrigger(s.name, "1", now.Add(-9*time.Minute)))
+	for deadline := time.Now().Add(2 * time.Second); !repository.getTrigger("1").IsTrigger; {
+		if time.Now().After(deadline) {
+			t.Fatal("expected trigger 1 was claimed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(claimed)
+	waitJobStatus(t, repository, "4", constants.JOB_STATUS_SUCCESS)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if strings.Join(order, ",") != "2,1,3,4" || maxActive != 1 {
+		t.Fatalf("expected job was run one by one by order of tick, got %v with %d concurrent", order, maxActive)
+	}
+}
+
+func TestDispatcherReleaseStaleClaim(t *testing.T) {
+	dispatcher, s, repository := newDispatcherTest(t, func(ctx context.Context) {})
+	staleAt := time.Now().Add(-time.Hour)
+	for _, jobId := range []string{"stale", "saved", "owned"} {
+		trigger := newDueTrigger(s.name, jobId, staleAt)
+		trigger.IsTrigger, trigger.NodeId, trigger.ClaimedAt = true, "dead", &staleAt
+		if jobId == "owned" {
+			trigger.NodeId = "node"
+		}
+		repository.UpsertTrigger(context.Background(), trigger)
+	}
+	/* job of trigger was saved before node was stopped, it is recovered by orphan detector */
+	repository.UpsertJob(context.Background(), &models.Job{SchedulerName: s.name, JobId: "saved", Status: constants.JOB_STATUS_RUNNING})
+	dispatcher.running["owned"] = true
+
+	dispatcher.renewClaims()
+	if trigger := repository.getTrigger("owned"); !trigger.IsTrigger || trigger.ClaimedAt == nil || time.Since(*trigger.ClaimedAt) > time.Minute {
+		t.Fatalf("expected claim of running trigger was renewed, got %+v", trigger)
+	}
+	if trigger := repository.getTrigger("saved"); !trigger.IsTrigger {
+		t.Fatal("expected claim of trigger which job was saved was kept")
+	}
+	if trigger := repository.getTrigger("stale"); trigger.IsTrigger || trigger.NodeId != "" {
+		t.Fatalf("expected stale claim was released, got %+v", trigger)
+	}
+
+	/* released trigger is claimed again */
+	delete(dispatcher.running, "owned")
+	dispatcher.dispatch()
+	waitJobStatus(t, repository, "stale", constants.JOB_STATUS_SUCCESS)
+	if trigger := repository.getTrigger("stale"); trigger.NodeId != "node" {
+		t.Fatalf("expected stale trigger was claimed by node, got %s", trigger.NodeId)
+	}
+}
+
+func TestDispatcherSchedulerRemoved(t *testing.T) {
+	dispatcher, _, repository := newDispatcherTest(t, func(ctx context.Context) {})
+	trigger := newDueTrigger("removed", "removed", time.Now())
+	dispatcher.running[trigger.JobId] = true
+
+	dispatcher.run(trigger)
+	if released := repository.getReleased(); len(released) != 1 || released[0] != "removed" {
+		t.Fatalf("expected trigger of removed scheduler was released, got %v", released)
+	}
+	if len(dispatcher.running) != 0 {
+		t.Fatal("expected trigger was not running")
+	}
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+type taskEdge struct {
+	upstream   task.Execution
+	downstream task.Execution
+}
+
+func (e taskEdge) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		Upstream   string `json:"upstream"`
+		Downstream string `json:"downstream"`
+	}
+	sh := ptr{
+		Upstream:   e.upstream.GetId(),
+		Downstream: e.downstream.GetId(),
+	}
+	return json.Marshal(sh)
+}
+
+type taskNode struct {
+	task        task.Execution
+	upstreams   []*taskNode
+	downstreams []*taskNode
+}
+
+type taskGraph struct {
+	nodes []*taskNode // sort by order of add task
+	edges []taskEdge
+}
+
+/*
+task which has not any edge in job will be run by order of add task
+(task1 >> task2 >> task3)
+*/
+func newLinearTaskGraph(tasks []task.Execution) *taskGraph {
+	var edges = make([]taskEdge, 0)
+	for index := 1; index < len(tasks); index++ {
+		edges = append(edges, taskEdge{upstream: tasks[index-1], downstream: tasks[index]})
+	}
+	graph, _ := newTaskGraph(tasks, edges)
+	return graph
+}
+
+func newTaskGraph(tasks []task.Execution, edges []taskEdge) (*taskGraph, error) {
+	var graph = &taskGraph{
+		nodes: make([]*taskNode, 0, len(tasks)),
+		edges: edges,
+	}
+	var nodes = make(map[task.Execution]*taskNode)
+	for _, taskExecution := range tasks {
+		if _, ok := nodes[taskExecution]; ok {
+			return nil, fmt.Errorf("task %s was added in job more than once", taskExecution.GetName())
+		}
+		node := &taskNode{task: taskExecution}
+		nodes[taskExecution] = node
+		graph.nodes = append(graph.nodes, node)
+	}
+
+	for _, edge := range edges {
+		upstream, ok := nodes[edge.upstream]
+		if !ok {
+			return nil, fmt.Errorf("task %s is not added in job", edge.upstream.GetName())
+		}
+		downstream, ok := nodes[edge.downstream]
+		if !ok {
+			return nil, fmt.Errorf("task %s is not added in job", edge.downstream.GetName())
+		}
+		if upstream == downstream {
+			return nil, fmt.Errorf("task %s can not depend on itself", edge.upstream.GetName())
+		}
+		upstream.downstreams = append(upstream.downstreams, downstream)
+		downstream.upstreams = append(downstream.upstreams, upstream)
+	}
+
+	if err := graph.validateCycle(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+/* kahn's algorithm, task which can not be visited are in cycle */
+func (g *taskGraph) validateCycle() error {
+	var inDegree = make(map[*taskNode]int, len(g.nodes))
+	var queue = make([]*taskNode, 0)
+	for _, node := range g.nodes {
+		inDegree[node] = len(node.upstreams)
+		if inDegree[node] == 0 {
+			queue = append(queue, node)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, downstream := range node.downstreams {
+			inDegree[downstream]--
+			if inDegree[downstream] == 0 {
+				queue = append(queue, downstream)
+			}
+		}
+	}
+
+	if visited != len(g.nodes) {
+		var names = make([]string, 0)
+		for _, node := range g.nodes {
+			if inDegree[node] > 0 {
+				names = append(names, node.task.GetId())
+			}
+		}
+		return fmt.Errorf("cycle detected between tasks: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func (g *taskGraph) getRoots() []*taskNode {
+	var roots = make([]*taskNode, 0)
+	for _, node := range g.nodes {
+		if len(node.upstreams) == 0 {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+func (g *taskGraph) getEdges() []taskEdge {
+	return g.edges
+}
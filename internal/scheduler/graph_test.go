@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func newNopTask(name string) task.Execution {
+	return task.NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	}))
+}
+
+func TestNewTaskGraph(t *testing.T) {
+	a, b, c, d := newNopTask("a"), newNopTask("b"), newNopTask("c"), newNopTask("d")
+	if err := task.AssignIds([]task.Execution{a, b, c, d}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		tasks []task.Execution
+		edges []taskEdge
+		err   string
+		roots int
+	}{
+		{name: "no edge", tasks: []task.Execution{a, b, c}, roots: 3},
+		{name: "diamond", tasks: []task.Execution{a, b, c, d}, edges: []taskEdge{{a, b}, {a, c}, {b, d}, {c, d}}, roots: 1},
+		{name: "self", tasks: []task.Execution{a, b}, edges: []taskEdge{{a, a}}, err: "can not depend on itself"},
+		{name: "not added", tasks: []task.Execution{a}, edges: []taskEdge{{a, b}}, err: "task b is not added in job"},
+		{name: "added twice", tasks: []task.Execution{a, a}, err: "more than once"},
+		{name: "cycle", tasks: []task.Execution{a, b, c, d}, edges: []taskEdge{{a, b}, {b, c}, {c, b}, {c, d}}, err: "cycle detected between tasks: b, c, d"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			graph, err := newTaskGraph(tc.tasks, tc.edges)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if roots := len(graph.getRoots()); roots != tc.roots {
+				t.Fatalf("expected %d roots, got %d", tc.roots, roots)
+			}
+		})
+	}
+}
+
+func TestNewLinearTaskGraph(t *testing.T) {
+	a, b, c := newNopTask("a"), newNopTask("b"), newNopTask("c")
+	graph := newLinearTaskGraph([]task.Execution{a, b, c})
+	if len(graph.getEdges()) != 2 {
+		t.Fatalf("expected 2 edges, got %d", len(graph.getEdges()))
+	}
+	roots := graph.getRoots()
+	if len(roots) != 1 || roots[0].task != a {
+		t.Fatalf("expected root a, got %v", roots)
+	}
+}
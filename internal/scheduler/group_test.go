@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func TestJobAddGroup(t *testing.T) {
+	var mutex sync.Mutex
+	var order = make([]string, 0)
+	record := func(name string) task.Execution {
+		return newResultTaskFunc(name, func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, name)
+		})
+	}
+	etl := task.NewTaskGroup("etl")
+	etl.AddTask(record("extract"), record("load"))
+
+	/* group is reusable on many job, task and group without edge run by order of add */
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			order = order[:0]
+			job := NewJob(nil)
+			job.AddTask(record("start"))
+			if _, err := job.AddGroup(etl); err != nil {
+				t.Fatal(err)
+			}
+			job.AddTask(record("end"))
+
+			repository := runTestJob(t, job)
+			if repository.job.Status != constants.JOB_STATUS_SUCCESS {
+				t.Fatalf("expected job success, got %s", repository.job.Status)
+			}
+			if strings.Join(order, ",") != "start,extract,load,end" {
+				t.Fatalf("unexpected order %v", order)
+			}
+			jobTask, ok := repository.tasks["etl.extract"]
+			if !ok || jobTask.TaskGroup != "etl" {
+				t.Fatalf("expected task saved by prefixed name with group, got %+v", jobTask)
+			}
+		})
+	}
+}
+
+func TestJobAddGroupDependency(t *testing.T) {
+	group := task.NewTaskGroup("etl")
+	group.AddTask(newNopTask("extract"), newNopTask("load"))
+	job := NewJob(nil)
+	start, end := newNopTask("start"), newNopTask("end")
+	job.AddTask(start, end)
+	embedded, err := job.AddGroup(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.SetDownstream(start, embedded.GetRoots()...)
+	job.SetUpstream(end, embedded.GetLeaves()...)
+	if err := job.buildGraph(); err != nil {
+		t.Fatal(err)
+	}
+
+	var edges = make([]string, 0)
+	for _, edge := range job.graph.edges {
+		edges = append(edges, edge.upstream.GetName()+">>"+edge.downstream.GetName())
+	}
+	expected := "etl.extract>>etl.load,start>>etl.extract,etl.load>>end"
+	if strings.Join(edges, ",") != expected {
+		t.Fatalf("expected edges %s, got %v", expected, edges)
+	}
+
+	if _, err := job.AddGroup(group); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("expected duplicated group error, got %v", err)
+	}
+}
@@ -18,6 +18,10 @@ type JobInstance struct {
 	scheduler     *SchedulerInstance
 	Job           *gocron.Job
 	tasks         []task.Execution
+	edges         []taskEdge
+	groups        []*task.TaskGroup // group which was embedded in job
+	groupEdges    []taskEdge        // dependency inside group
+	graph         *taskGraph
 	arguments     map[string]interface{} // kargs of any process
 	status        string
 	totalTask     int
@@ -27,9 +31,12 @@ type JobInstance struct {
 
 func NewJob(arguments map[string]interface{}) *JobInstance {
 	ji := &JobInstance{
-		tasks:     make([]task.Execution, 0),
-		arguments: make(map[string]interface{}),
-		totalTask: 0,
+		tasks:      make([]task.Execution, 0),
+		edges:      make([]taskEdge, 0),
+		groups:     make([]*task.TaskGroup, 0),
+		groupEdges: make([]taskEdge, 0),
+		arguments:  make(map[string]interface{}),
+		totalTask:  0,
 	}
 	if arguments != nil {
 		ji.arguments = arguments
@@ -43,6 +50,93 @@ func (j *JobInstance) AddTask(tasks ...task.Execution) {
 	j.totalTask = len(j.tasks)
 }
 
+/*
+upstream >> downstreams, downstreams will be start when upstream was finished
+if job has not any dependency, task will be run by order of AddTask
+*/
+func (j *JobInstance) SetDownstream(upstream task.Execution, downstreams ...task.Execution) {
+	for _, downstream := range downstreams {
+		j.edges = append(j.edges, taskEdge{upstream: upstream, downstream: downstream})
+	}
+}
+
+/* upstreams >> downstream, downstream will be start when all upstreams were finished */
+func (j *JobInstance) SetUpstream(downstream task.Execution, upstreams ...task.Execution) {
+	for _, upstream := range upstreams {
+		j.edges = append(j.edges, taskEdge{upstream: upstream, downstream: downstream})
+	}
+}
+
+/*
+embed copy of group in job, name of task in group is prefixed by name of group (group.task).
+returned group is used for set dependency with another task by GetRoots and GetLeaves
+*/
+func (j *JobInstance) AddGroup(group *task.TaskGroup) (*task.TaskGroup, error) {
+	embedded, err := group.Embed()
+	if err != nil {
+		return nil, err
+	}
+	for _, existed := range j.groups {
+		if existed.GetName() == embedded.GetName() {
+			return nil, fmt.Errorf("group %s was added in job more than once", embedded.GetName())
+		}
+	}
+	j.AddTask(embedded.GetTasks()...)
+	for _, edge := range embedded.GetEdges() {
+		j.groupEdges = append(j.groupEdges, taskEdge{upstream: edge.Upstream, downstream: edge.Downstream})
+	}
+	j.groups = append(j.groups, embedded)
+	return embedded, nil
+}
+
+/* task and group which has not any edge in job will be run by order of add (task1 >> group1 >> task2) */
+func (j *JobInstance) linearGroupEdges() []taskEdge {
+	var groupOf = make(map[task.Execution]*task.TaskGroup)
+	for _, group := range j.groups {
+		for _, groupTask := range group.GetTasks() {
+			groupOf[groupTask] = group
+		}
+	}
+
+	var edges = make([]taskEdge, 0)
+	var previous []task.Execution
+	var visited = make(map[*task.TaskGroup]bool)
+	for _, taskExecution := range j.tasks {
+		var roots, leaves = []task.Execution{taskExecution}, []task.Execution{taskExecution}
+		if group, ok := groupOf[taskExecution]; ok {
+			if visited[group] {
+				continue
+			}
+			visited[group] = true
+			roots, leaves = group.GetRoots(), group.GetLeaves()
+		}
+		for _, upstream := range previous {
+			for _, downstream := range roots {
+				edges = append(edges, taskEdge{upstream: upstream, downstream: downstream})
+			}
+		}
+		previous = leaves
+	}
+	return edges
+}
+
+func (j *JobInstance) buildGraph() error {
+	if len(j.edges) == 0 && len(j.groups) == 0 {
+		j.graph = newLinearTaskGraph(j.tasks)
+		return nil
+	}
+	var edges = j.edges
+	if len(edges) == 0 {
+		edges = j.linearGroupEdges()
+	}
+	graph, err := newTaskGraph(j.tasks, append(append(make([]taskEdge, 0), j.groupEdges...), edges...))
+	if err != nil {
+		return err
+	}
+	j.graph = graph
+	return nil
+}
+
 func (j JobInstance) GetTask(taskIndex int) task.Execution {
 	return j.tasks[taskIndex]
 }
@@ -53,16 +147,19 @@ func (j JobInstance) GetTotalTask() int {
 
 func (j *JobInstance) SetScheduler(scheudler *SchedulerInstance) {
 	j.scheduler = scheudler
-	if scheudler.config.JobMode == constants.JOB_MODE_SIGNLETON && scheudler.cronExpression != "" {
-		j.Job.SingletonMode()
-	}
 	j.logger = logger.NewLoggerWithFile(constants.LOG_PATH_RESULT_JOB(scheudler.name))
 }
 
 func (j *JobInstance) trigger(overrideJobId string, triggerConfig *sync.Map, executeDatetime *time.Time) (jobId string, fn func()) {
 	return overrideJobId, func() {
 		ctx := context.Background()
-		runner := newJobRunner(ctx, j, triggerConfig, executeDatetime)
+		/* logical datetime of cronjob is scheduled tick, job which was fired late is deduplicated with backfill by tick */
+		logicalDatetime := executeDatetime
+		if logicalDatetime == nil {
+			tick := j.scheduler.GetCronTick(time.Now())
+			logicalDatetime = &tick
+		}
+		runner := newJobRunner(ctx, j, triggerConfig, logicalDatetime)
 		runner.triggerType = constants.TRIGGER_TYPE_SCHEDULE
 		if overrideJobId != "" {
 			runner.id = overrideJobId
@@ -91,9 +188,22 @@ func (j *JobInstance) trigger(overrideJobId string, triggerConfig *sync.Map, exe
 func (j *JobInstance) process(runner *jobRunner) {
 	switch runner.triggerType {
 	case constants.TRIGGER_TYPE_SCHEDULE:
+		/* follower does not fire cronjob, lease is validated again when trigger is created */
+		lease, isLeader := j.scheduler.fencingLease()
+		if !isLeader {
+			return
+		}
+		/* scheduler may be paused by other replica */
+		if err := j.scheduler.LoadState(context.Background()); err != nil {
+			log.Errorf("failed to load scheduler state with error: %s", err.Error())
+			return
+		}
+		if j.scheduler.IsPaused() {
+			return
+		}
 		trigger := &models.Trigger{
 			SchedulerName:   j.scheduler.name,
-			ExecuteDatetime: time.Now(),
+			ExecuteDatetime: runner.executeDatetime,
 			JobId:           runner.id,
 			Config:          nil,
 			TriggerType:     constants.TRIGGER_TYPE_SCHEDULE,
@@ -102,8 +212,8 @@ func (j *JobInstance) process(runner *jobRunner) {
 			CreatedAt:       time.Now(),
 			UpdatedAt:       time.Now(),
 		}
-		if err := j.scheduler.dbAdapter.GetRepository().CreateTriggerByJobScheduler(context.Background(), trigger); err != nil {
-			if err.Error() == constants.ERROR_ALREADY_EXISTS {
+		if err := j.scheduler.dbAdapter.GetRepository().CreateTriggerByJobScheduler(context.Background(), trigger, lease); err != nil {
+			if err.Error() == constants.ERROR_ALREADY_EXISTS || err.Error() == constants.ERROR_LEASE_LOST {
 				return
 			}
 			log.Errorf("failed to create trigger by job scheduler with error: %s", err.Error())
@@ -111,7 +221,22 @@ func (j *JobInstance) process(runner *jobRunner) {
 		}
 	}
 
+	/* job is not started after shutdown */
+	if !j.scheduler.trackJob(runner) {
+		j.scheduler.handBack(runner)
+		return
+	}
+	defer j.scheduler.untrackJob(runner)
+
+	/* wait for slot of MaxActiveConcurrent, slot is released when every task was finished */
+	if err := runner.slot.acquire(j.scheduler.shutdownCtx()); err != nil {
+		j.scheduler.handBack(runner)
+		return
+	}
+	defer runner.slot.release()
+
 	runner.setStartProcess()
+	runner.logjob.NodeId = constants.ENV_NODE_ID
 	if err := j.scheduler.GetAdapter().GetRepository().UpsertJob(runner.ctx, runner.logjob); err != nil {
 		fmt.Println("fail to upsert job with status Before processing:", err.Error())
 	}
@@ -123,9 +248,10 @@ func (j *JobInstance) process(runner *jobRunner) {
 	}()
 
 	defer runner.clear()
-	runner.run(runner.tasks)
+	runner.run(j.graph)
 	if runner.exception != nil {
-		if runner.logtaskrunning != nil {
+		/* task was stopped by panic */
+		if runner.logtaskrunning != nil && runner.logtaskrunning.Status == constants.JOB_STATUS_RUNNING {
 			runner.logtaskrunning.Status = constants.JOB_STATUS_FAILED
 			runner.logtaskrunning.UpdatedAt = time.Now()
 			runner.logtaskrunning.TaskException = runner.exception.Error()
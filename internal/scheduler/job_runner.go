@@ -3,8 +3,10 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,11 +25,16 @@ type JobRunner interface {
 	GetTask() task.Execution
 	GetException() Exception
 	GetExecuteDatetime() time.Time
-	GetArguments() *sync.Map     // static data when job run
-	GetParameter() *sync.Map     // pass data through pipeline
-	GetTriggerConfig() *sync.Map // pass data when trigger
-	GetTaskValue(taskName string) (data interface{}, ok bool)
+	GetArguments() *sync.Map                                                                                             // static data when job run
+	GetParameter() *sync.Map                                                                                             // pass data through pipeline
+	GetTriggerConfig() *sync.Map                                                                                         // pass data when trigger
+	GetTaskValue(taskId string) (data interface{}, ok bool)                                                              // value of task by id, id of task which is not in branch is name of task
+	GetJobTaskValue(ctx context.Context, jobId string, taskId string) (data interface{}, ok bool, err error)             // value of task which was saved by another job
+	GetLastestTaskValue(ctx context.Context, schedulerName string, taskId string) (data interface{}, ok bool, err error) // value of task on lastest success job of scheduler
 	GetLogger() *logger.Log
+	TriggerDag(ctx context.Context, schedulerName string, config map[string]interface{}) (jobId string, err error)
+	GetJob(ctx context.Context, jobId string) (*models.Job, error)
+	GetLastestJob(ctx context.Context, schedulerName string, since time.Time, statuses ...constants.JobStatus) (*models.Job, error) // lastest job of scheduler which was started since datetime
 }
 
 type Exception interface {
@@ -38,10 +45,13 @@ type Exception interface {
 type jobRunner struct {
 	id                  string
 	schedulerName       string
-	ctx                 context.Context
+	ctx                 context.Context // context of job runner instance
+	jobCtx              context.Context // context of job deadline which pass to task
+	cancel              context.CancelFunc
+	mutex               *sync.Mutex
 	tasks               []task.Execution
 	status              constants.JobStatus
-	currentTaskIndex    int
+	currentTask         task.Execution
 	exceptionOnTaskName string
 	exception           Exception
 	executeDatetime     time.Time
@@ -52,15 +62,25 @@ type jobRunner struct {
 	logger              *logger.Log
 	taskResults         []taskResult
 	triggerConfig       *sync.Map
+	config              SchedulerConfig
 	dbAdapter           connection.DatabaseAdapterConnection
+	registry            *Registry
+	slot                *jobSlot
 	triggerType         constants.TriggerType
-	logjob              *models.Job     // for save in db
-	logtaskrunning      *models.JobTask // for save in db
+	rerunOf             *models.Job                // job of previous run, nil when job was not rerun
+	logjob              *models.Job                // for save in db
+	logtaskrunning      *models.JobTask            // for save in db
+	attempts            map[string]int             // lastest attempt of task on previous run
+	restored            map[string]*models.JobTask // task which was success on previous run, restore instead of run
+	tolerated           map[string]bool            // id prefix of pipeline which failure does not fail job
+	slaMissed           *sync.Map                  // id of task which missed sla
+	unheldSlots         *sync.Map                  // id of sensor which released slot and was cancelled before hold it again
 }
 
 type taskResult struct {
 	task        task.Execution
 	status      constants.JobStatus
+	attempt     int
 	startDate   time.Time
 	endDatetime *time.Time
 }
@@ -90,18 +110,30 @@ func (r *runnerException) StackTrace() string {
 func newJobRunner(ctx context.Context, ji *JobInstance, triggerConfig *sync.Map, executeDatetime *time.Time) *jobRunner {
 	uid, _ := uuid.NewV4()
 	runner := &jobRunner{
-		id:               uid.String(),
-		schedulerName:    ji.scheduler.name,
-		ctx:              ctx,
-		tasks:            ji.tasks,
-		status:           constants.JOB_STATUS_WAITING,
-		currentTaskIndex: 0,
-		executeDatetime:  time.Now(),
-		arguments:        new(sync.Map),
-		parameter:        new(sync.Map),
-		taskValue:        new(sync.Map),
-		triggerConfig:    new(sync.Map),
-		dbAdapter:        ji.scheduler.dbAdapter,
+		id:              uid.String(),
+		schedulerName:   ji.scheduler.name,
+		ctx:             ctx,
+		mutex:           new(sync.Mutex),
+		tasks:           ji.tasks,
+		status:          constants.JOB_STATUS_WAITING,
+		executeDatetime: time.Now(),
+		arguments:       new(sync.Map),
+		parameter:       new(sync.Map),
+		taskValue:       new(sync.Map),
+		taskResults:     make([]taskResult, 0),
+		triggerConfig:   new(sync.Map),
+		config:          ji.scheduler.config,
+		dbAdapter:       ji.scheduler.dbAdapter,
+		registry:        ji.scheduler.registry,
+		slot:            newJobSlot(ji.scheduler.slots),
+		attempts:        make(map[string]int),
+		restored:        make(map[string]*models.JobTask),
+		tolerated:       make(map[string]bool),
+		slaMissed:       new(sync.Map),
+		unheldSlots:     new(sync.Map),
+	}
+	if len(ji.tasks) > 0 {
+		runner.currentTask = ji.tasks[0]
 	}
 
 	if ji.arguments != nil {
@@ -123,134 +155,457 @@ func (jr *jobRunner) getRunnerInterface() JobRunner {
 	return jr
 }
 
-func (jr jobRunner) GetId() string {
+func (jr *jobRunner) GetId() string {
 	return jr.id
 }
 
-func (jr jobRunner) GetSchedulerName() string {
+func (jr *jobRunner) GetSchedulerName() string {
 	return jr.schedulerName
 }
 
-func (jr jobRunner) GetStatus() constants.JobStatus {
+func (jr *jobRunner) GetStatus() constants.JobStatus {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
 	return jr.status
 }
 
-func (jr jobRunner) GetTask() task.Execution {
-	return jr.tasks[jr.currentTaskIndex]
+/* task which was started lastest, when job was failed it is task which raise exception */
+func (jr *jobRunner) GetTask() task.Execution {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	return jr.currentTask
 }
 
-func (jr jobRunner) GetException() Exception {
+func (jr *jobRunner) GetException() Exception {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
 	return jr.exception
 }
 
-func (jr jobRunner) GetExecuteDatetime() time.Time {
+func (jr *jobRunner) GetExecuteDatetime() time.Time {
 	return jr.executeDatetime
 }
 
-func (jr jobRunner) GetArguments() *sync.Map {
+func (jr *jobRunner) GetArguments() *sync.Map {
 	return jr.arguments
 }
 
-func (jr jobRunner) GetParameter() *sync.Map {
+func (jr *jobRunner) GetParameter() *sync.Map {
 	return jr.parameter
 }
 
-func (jr jobRunner) GetTriggerConfig() *sync.Map {
+func (jr *jobRunner) GetTriggerConfig() *sync.Map {
 	return jr.triggerConfig
 }
 
-func (jr jobRunner) GetTaskValue(taskName string) (data interface{}, ok bool) {
-	return jr.taskValue.Load(taskName)
+func (jr *jobRunner) GetTaskValue(taskId string) (data interface{}, ok bool) {
+	return jr.taskValue.Load(taskId)
+}
+
+func (jr *jobRunner) GetJobTaskValue(ctx context.Context, jobId string, taskId string) (data interface{}, ok bool, err error) {
+	return jr.loadTaskValue(ctx, "", jobId, taskId)
+}
+
+func (jr *jobRunner) GetLastestTaskValue(ctx context.Context, schedulerName string, taskId string) (data interface{}, ok bool, err error) {
+	return jr.loadTaskValue(ctx, schedulerName, "", taskId)
+}
+
+/* value is deserialized by serializer of scheduler which saved it */
+func (jr *jobRunner) loadTaskValue(ctx context.Context, schedulerName string, jobId string, taskId string) (interface{}, bool, error) {
+	jobTask, err := jr.dbAdapter.GetRepository().GetLastestSuccessJobTask(ctx, schedulerName, jobId, taskId)
+	if err != nil {
+		return nil, false, err
+	}
+	if jobTask == nil || jobTask.TaskValue == "" {
+		return nil, false, nil
+	}
+
+	var config = jr.config
+	if jobTask.SchedulerName != jr.schedulerName && jr.registry != nil {
+		if schedulerInstance := jr.registry.Get(jobTask.SchedulerName); schedulerInstance != nil {
+			config = schedulerInstance.config
+		}
+	}
+	return deserializeValue(config, jobTask.TaskValue), true, nil
 }
 
-func (jr jobRunner) GetLogger() *logger.Log {
+func (jr *jobRunner) GetLogger() *logger.Log {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
 	return jr.logger
 }
 
-func (jr *jobRunner) run(tasks []task.Execution) {
+func recoverError(r interface{}) error {
+	if reflect.TypeOf(r).Kind() == reflect.String {
+		return errors.New(reflect.ValueOf(r).String())
+	}
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
+
+/*
+call task with deadline of task, task which not handle context
+will be released when deadline was exceeded
+*/
+func (jr *jobRunner) call(taskExecution task.Execution, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	type result struct {
+		value interface{}
+		err   error
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout := jr.getTaskTimeout(taskExecution); timeout > 0 {
+		ctx, cancel = context.WithTimeout(jr.jobCtx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(jr.jobCtx)
+	}
+	defer cancel()
+	/* executor resolve name of task in same pipeline by id of task which is called */
+	ctx = context.WithValue(ctx, constants.TASK_KEY, taskExecution)
+
+	ch := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- result{err: recoverError(r)}
+			}
+		}()
+		value, err := fn(ctx)
+		ch <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-ch:
+		return res.value, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (jr *jobRunner) run(graph *taskGraph) {
 	defer func() {
 		if r := recover(); r != nil {
-			if reflect.TypeOf(r).Kind() == reflect.String {
-				jr.exception = newRunnerException(errors.New(reflect.ValueOf(r).String()), true)
-				jr.setStatus(constants.JOB_STATUS_FAILED)
-			} else {
-				jr.exception = newRunnerException(r.(error), true)
-				jr.setStatus(constants.JOB_STATUS_FAILED)
-			}
+			jr.setException(nil, recoverError(r))
+			jr.setStatus(constants.JOB_STATUS_FAILED)
 		}
 	}()
 
-	/* save processing on task but without error */
-	saveJobTask := func(runner *jobRunner, taskExecution task.Execution, taskResult taskResult) error {
-		jobtask := &models.JobTask{
-			JobId:         jr.id,
-			SchedulerName: jr.schedulerName,
-			Status:        taskResult.status,
-			TaskName:      taskExecution.GetName(),
-			TaskType:      string(taskExecution.GetType()),
-			ExecutionName: taskExecution.GetExecutionName(),
-			StartDateTime: taskResult.startDate,
-			EndDatetime:   taskResult.endDatetime,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
+	jr.setStatus(constants.JOB_STATUS_RUNNING)
+	/* slot of job was handed to root tasks */
+	if jr.runGraph(graph, jr.slot.release, nil) {
+		jr.setStatus(constants.JOB_STATUS_SUCCESS)
+	}
+}
+
+/* release slot which was held by task, sensor which did not hold slot again after reschedule has nothing to release */
+func (jr *jobRunner) releaseTaskSlot(taskExecution task.Execution) {
+	if _, ok := jr.unheldSlots.LoadAndDelete(taskExecution.GetId()); ok {
+		return
+	}
+	jr.slot.release()
+}
+
+/*
+run task on graph concurrently, task will be considered by trigger rule
+when all upstreams were finished. return false when any task in graph was failed.
+started is called when every root task was started, task is skipped when isCancelled return true
+*/
+func (jr *jobRunner) runGraph(graph *taskGraph, started func(), isCancelled func() bool) bool {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var isSuccess = true
+	var statuses = make(map[*taskNode]constants.JobStatus, len(graph.nodes))
+	var circuited = make(map[*taskNode]bool) // downstream of short circuit task which return false
+	var pending = make(map[*taskNode]int, len(graph.nodes))
+	for _, node := range graph.nodes {
+		pending[node] = len(node.upstreams)
+	}
+
+	var start func(node *taskNode)
+	finish := func(node *taskNode, status constants.JobStatus) {
+		var readys = make([]*taskNode, 0)
+		mutex.Lock()
+		statuses[node] = status
+		if isFailedStatus(status) {
+			isSuccess = false
 		}
-		if runner.exception != nil {
-			jobtask.TaskException = runner.exception.Error()
-			jobtask.StackTrace = runner.exception.StackTrace()
+		for _, downstream := range node.downstreams {
+			pending[downstream]--
+			if pending[downstream] == 0 {
+				readys = append(readys, downstream)
+			}
+		}
+		mutex.Unlock()
+		for _, ready := range readys {
+			start(ready)
 		}
-		jr.logtaskrunning = jobtask
-		return jr.dbAdapter.GetRepository().UpsertJobTask(jr.ctx, jobtask)
 	}
+	start = func(node *taskNode) {
+		/* downstream hold slot before upstream release it */
+		isHeld := jr.slot.acquire(jr.jobCtx) == nil
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var status = constants.JOB_STATUS_FAILED
+			defer func() {
+				if r := recover(); r != nil {
+					jr.fail(node.task, constants.JOB_STATUS_FAILED, recoverError(r))
+					status = constants.JOB_STATUS_FAILED
+				}
+				finish(node, status)
+				if isHeld {
+					jr.releaseTaskSlot(node.task)
+				}
+			}()
+
+			var upstreamStatuses = make([]constants.JobStatus, 0, len(node.upstreams))
+			mutex.Lock()
+			for _, upstream := range node.upstreams {
+				upstreamStatuses = append(upstreamStatuses, statuses[upstream])
+			}
+			isCircuited := circuited[node]
+			mutex.Unlock()
 
-	jr.setStatus(constants.JOB_STATUS_RUNNING)
-	jr.tasks = tasks
-	jr.currentTaskIndex = 0
-	jr.taskResults = make([]taskResult, len(jr.tasks))
-	for index, taskExecution := range tasks {
-		pathfile := constants.LOG_PATH_RUNNER_TASK(jr.schedulerName, jr.executeDatetime, taskExecution.GetName())
-		jr.logger = logger.NewLoggerWithFile(pathfile)
-
-		taskResult := taskResult{
-			status:    constants.JOB_STATUS_RUNNING,
-			startDate: time.Now(),
+			if isCircuited || (isCancelled != nil && isCancelled()) || !isTriggerRuleMatched(node.task.GetTriggerRule(), upstreamStatuses) {
+				status = jr.skipTask(node.task)
+				return
+			}
+			status = jr.runTask(node.task)
+			if jr.isShortCircuited(node.task, status) {
+				mutex.Lock()
+				var visit func(node *taskNode)
+				visit = func(node *taskNode) {
+					for _, downstream := range node.downstreams {
+						if !circuited[downstream] {
+							circuited[downstream] = true
+							visit(downstream)
+						}
+					}
+				}
+				visit(node)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, root := range graph.getRoots() {
+		start(root)
+	}
+	if started != nil {
+		started()
+	}
+	wg.Wait()
+
+	return isSuccess
+}
+
+/* short circuit task which was success with false skip every downstream task regardless of trigger rule */
+func (jr *jobRunner) isShortCircuited(taskExecution task.Execution, status constants.JobStatus) bool {
+	shortCircuit, ok := taskExecution.(*task.TaskShortCircuit)
+	if !ok || status != constants.JOB_STATUS_SUCCESS {
+		return false
+	}
+	value, _ := jr.taskValue.Load(shortCircuit.GetId())
+	return shortCircuit.IsShortCircuited(value)
+}
+
+/* save processing on task */
+func (jr *jobRunner) saveJobTask(taskExecution task.Execution, taskResult taskResult, value interface{}, exception Exception) error {
+	jobtask := &models.JobTask{
+		JobId:         jr.id,
+		SchedulerName: jr.schedulerName,
+		Status:        taskResult.status,
+		TaskId:        taskExecution.GetId(),
+		TaskName:      taskExecution.GetName(),
+		TaskType:      string(taskExecution.GetType()),
+		ExecutionName: taskExecution.GetExecutionName(),
+		Attempt:       taskResult.attempt,
+		StartDateTime: taskResult.startDate,
+		EndDatetime:   taskResult.endDatetime,
+		TaskValue:     jr.encodeTaskValue(taskExecution.GetId(), value),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	jobtask.SlaMissed = jr.isSlaMissed(taskExecution)
+	if grouped, ok := taskExecution.(interface{ GetGroup() string }); ok {
+		jobtask.TaskGroup = grouped.GetGroup()
+	}
+	if exception != nil {
+		jobtask.TaskException = exception.Error()
+		jobtask.StackTrace = exception.StackTrace()
+	}
+	jr.mutex.Lock()
+	jr.logtaskrunning = jobtask
+	jr.mutex.Unlock()
+	return jr.dbAdapter.GetRepository().UpsertJobTask(context.Background(), jobtask)
+}
+
+func (jr *jobRunner) skipTask(taskExecution task.Execution) constants.JobStatus {
+	ti := time.Now()
+	taskResult := taskResult{
+		task:        taskExecution,
+		status:      constants.JOB_STATUS_SKIPPED,
+		attempt:     jr.attempts[taskExecution.GetId()] + 1,
+		startDate:   ti,
+		endDatetime: &ti,
+	}
+	jr.addTaskResult(taskResult)
+	jr.saveJobTask(taskExecution, taskResult, nil, nil)
+	return taskResult.status
+}
+
+/* return status of task, task branch will be failed when task in pipeline was failed */
+func (jr *jobRunner) runTask(taskExecution task.Execution) constants.JobStatus {
+	value, status, ok := jr.restoreTask(taskExecution)
+	if !ok {
+		if mapped, isMapped := taskExecution.(*task.TaskMapped); isMapped {
+			value, status = jr.executeMappedTask(mapped)
+		} else {
+			value, status = jr.executeTask(taskExecution)
 		}
-		// jr.logger.Info(fmt.Sprintf("scheduler %s with starting task %s", jr.schedulerName, taskExecution.GetName()), map[string]interface{}{"job_id": jr.id, "task_name": taskExecution.GetName(), "scheduler_name": jr.schedulerName, "task_start": taskResult.startDate.Format(constants.TIME_FORMAT_RFC339)})
+	}
+	if status != constants.JOB_STATUS_SUCCESS {
+		return status
+	}
 
-		jr.currentTaskIndex = index
+	switch taskExecution.GetType() {
+	case constants.TASK_TYPE_BRANCH_TASK:
+		if !jr.runBranch(taskExecution.(*task.TaskBranch), value.(task.TaskBranchPipeLines)) {
+			return constants.JOB_STATUS_FAILED
+		}
+	}
+	return status
+}
 
+/* call task with retry, attempt is continued from previous run of job */
+func (jr *jobRunner) executeTask(taskExecution task.Execution) (interface{}, constants.JobStatus) {
+	pathfile := constants.LOG_PATH_RUNNER_TASK(jr.schedulerName, jr.executeDatetime, taskExecution.GetName())
+	jr.mutex.Lock()
+	jr.currentTask = taskExecution
+	jr.logger = logger.NewLoggerWithFile(pathfile)
+	jr.mutex.Unlock()
+
+	taskResult := taskResult{
+		task:      taskExecution,
+		status:    constants.JOB_STATUS_RUNNING,
+		attempt:   jr.attempts[taskExecution.GetId()] + 1,
+		startDate: time.Now(),
+	}
+	// jr.logger.Info(fmt.Sprintf("scheduler %s with starting task %s", jr.schedulerName, taskExecution.GetName()), map[string]interface{}{"job_id": jr.id, "task_name": taskExecution.GetName(), "scheduler_name": jr.schedulerName, "task_start": taskResult.startDate.Format(constants.TIME_FORMAT_RFC339)})
+
+	stopSla := jr.watchSla(taskExecution)
+	defer stopSla()
+
+	var options = taskExecution.GetOptions()
+	var value interface{}
+	var err error
+	for retry := 0; ; retry++ {
 		/* save in db */
-		saveJobTask(jr, taskExecution, taskResult)
+		jr.saveJobTask(taskExecution, taskResult, nil, nil)
+
+		if sensor, ok := taskExecution.(*task.TaskSensor); ok {
+			value, err = jr.callSensor(sensor, &taskResult)
+		} else if jr.isQueued(taskExecution) {
+			attempt := taskResult.attempt
+			value, err = jr.call(taskExecution, func(ctx context.Context) (interface{}, error) {
+				return jr.callQueue(ctx, taskExecution, attempt)
+			})
+		} else {
+			value, err = jr.call(taskExecution, taskExecution.Call)
+		}
+		if err == nil || retry >= jr.getRetryTimes(taskExecution) || jr.jobCtx.Err() != nil {
+			break
+		}
 
-		value, err := taskExecution.Call(jr.ctx)
+		/* waiting for retry, each attempt is kept in history */
 		ti := time.Now()
+		taskResult.status = constants.JOB_STATUS_UP_FOR_RETRY
 		taskResult.endDatetime = &ti
-		if err != nil {
-			jr.exceptionOnTaskName = taskExecution.GetName()
-			jr.exception = newRunnerException(err, true)
-			taskResult.status = constants.JOB_STATUS_FAILED
-			jr.taskResults[index] = taskResult
-			// jr.logger.Error(err, map[string]interface{}{"job_id": jr.id, "task_name": taskExecution.GetName(), "scheduler_name": jr.schedulerName, "task_start": taskResult.startDate.Format(constants.TIME_FORMAT_RFC339), "task_end": taskResult.endDatetime.Format(constants.TIME_FORMAT_RFC339), "task_status": taskResult.status})
-			saveJobTask(jr, taskExecution, taskResult)
-			return
+		jr.saveJobTask(taskExecution, taskResult, nil, newRunnerException(err, true))
+		jr.callTaskCallback(taskExecution, options.OnTaskRetry, err)
+
+		select {
+		case <-time.After(jr.getRetryDelay(taskExecution, retry+1)):
+		case <-jr.jobCtx.Done():
+		}
+		if jr.jobCtx.Err() != nil {
+			err = jr.jobCtx.Err()
+			break
+		}
+		taskResult.status = constants.JOB_STATUS_RUNNING
+		taskResult.attempt++
+		taskResult.startDate = time.Now()
+		taskResult.endDatetime = nil
+	}
+	ti := time.Now()
+	taskResult.endDatetime = &ti
+	if err != nil {
+		taskResult.status = constants.JOB_STATUS_FAILED
+		if errors.Is(err, context.DeadlineExceeded) {
+			taskResult.status = constants.JOB_STATUS_TIMEOUT
 		}
-		taskResult.status = constants.JOB_STATUS_SUCCESS
-		saveJobTask(jr, taskExecution, taskResult)
+		exception := jr.fail(taskExecution, taskResult.status, err)
+		jr.addTaskResult(taskResult)
+		// jr.logger.Error(err, map[string]interface{}{"job_id": jr.id, "task_name": taskExecution.GetName(), "scheduler_name": jr.schedulerName, "task_start": taskResult.startDate.Format(constants.TIME_FORMAT_RFC339), "task_end": taskResult.endDatetime.Format(constants.TIME_FORMAT_RFC339), "task_status": taskResult.status})
+		jr.saveJobTask(taskExecution, taskResult, nil, exception)
+		jr.callTaskCallback(taskExecution, options.OnTaskFailure, err)
+		return nil, taskResult.status
+	}
+	taskResult.status = constants.JOB_STATUS_SUCCESS
+	jr.saveJobTask(taskExecution, taskResult, value, nil)
+	jr.callTaskCallback(taskExecution, options.OnTaskSuccess, nil)
 
-		jr.taskResults[index] = taskResult
-		jr.taskValue.Store(taskExecution.GetName(), value)
+	jr.addTaskResult(taskResult)
+	jr.taskValue.Store(taskExecution.GetId(), value)
 
-		// jr.logger.Info(fmt.Sprintf("scheduler %s with ending task %s", jr.schedulerName, taskExecution.GetName()), map[string]interface{}{"job_id": jr.id, "task_name": taskExecution.GetName(), "scheduler_name": jr.schedulerName, "task_start": taskResult.startDate.Format(constants.TIME_FORMAT_RFC339), "task_end": taskResult.endDatetime.Format(constants.TIME_FORMAT_RFC339), "task_status": taskResult.status})
+	// jr.logger.Info(fmt.Sprintf("scheduler %s with ending task %s", jr.schedulerName, taskExecution.GetName()), map[string]interface{}{"job_id": jr.id, "task_name": taskExecution.GetName(), "scheduler_name": jr.schedulerName, "task_start": taskResult.startDate.Format(constants.TIME_FORMAT_RFC339), "task_end": taskResult.endDatetime.Format(constants.TIME_FORMAT_RFC339), "task_status": taskResult.status})
+	return value, taskResult.status
+}
+
+func (jr *jobRunner) addTaskResult(taskResult taskResult) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	jr.taskResults = append(jr.taskResults, taskResult)
+}
 
-		switch taskExecution.GetType() {
-		case constants.TASK_TYPE_BRANCH_TASK:
-			val := value.(task.TaskBranchPipeLine)
-			tasks := val.GetTasks()
-			jr.run(tasks)
-			return
+/* keep only first exception of job */
+func (jr *jobRunner) setException(taskExecution task.Execution, err error) Exception {
+	exception := newRunnerException(err, true)
+
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	if jr.exception == nil {
+		jr.exception = exception
+		if taskExecution != nil {
+			jr.currentTask = taskExecution
+			jr.exceptionOnTaskName = taskExecution.GetId()
 		}
 	}
+	return exception
+}
 
-	jr.setStatus(constants.JOB_STATUS_SUCCESS)
+/* failed task set exception and status of job, except task in pipeline which was tolerated by branch */
+func (jr *jobRunner) fail(taskExecution task.Execution, status constants.JobStatus, err error) Exception {
+	if jr.isTolerated(taskExecution) {
+		return newRunnerException(err, true)
+	}
+	exception := jr.setException(taskExecution, err)
+	jr.setStatus(status)
+	return exception
+}
+
+func (jr *jobRunner) isTolerated(taskExecution task.Execution) bool {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	for prefix := range jr.tolerated {
+		if strings.HasPrefix(taskExecution.GetId(), prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 func (jr *jobRunner) clear() {
@@ -258,18 +613,52 @@ func (jr *jobRunner) clear() {
 }
 
 func (jr *jobRunner) setStartProcess() {
+	jr.mutex.Lock()
+	if jr.config.JobTimeout > 0 {
+		jr.jobCtx, jr.cancel = context.WithTimeout(jr.ctx, jr.config.JobTimeout)
+	} else {
+		jr.jobCtx, jr.cancel = context.WithCancel(jr.ctx)
+	}
+	jr.mutex.Unlock()
 	jr.setStatus(constants.JOB_STATUS_RUNNING)
 	jr.logjob.UpdatedAt = time.Now()
 }
 
 func (jr *jobRunner) setEndProcess() {
+	if jr.cancel != nil {
+		jr.cancel()
+	}
 	ti := time.Now()
 	jr.endDatetime = &ti
 	jr.logjob.EndDatetime = &ti
+	jr.logjob.ParameterString = jr.encodeParameter()
+	if exception := jr.GetException(); exception != nil {
+		jr.logjob.Exception = exception.Error()
+	}
 	jr.logjob.UpdatedAt = ti
 }
 
+/* cancel running task of job, job is saved as failed with err */
+func (jr *jobRunner) shutdown(err error) {
+	jr.setException(nil, err)
+	jr.setStatus(constants.JOB_STATUS_FAILED)
+
+	jr.mutex.Lock()
+	cancel := jr.cancel
+	jr.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+/* status of job keep first status which was not success */
 func (jr *jobRunner) setStatus(status constants.JobStatus) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	switch jr.status {
+	case constants.JOB_STATUS_FAILED, constants.JOB_STATUS_TIMEOUT:
+		return
+	}
 	jr.status = status
 	jr.logjob.Status = jr.status
 }
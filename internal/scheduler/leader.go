@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	default_leader_lease_ttl = 15 * time.Second
+)
+
+/*
+leader election by lease in database, lease is renewed every third of ttl.
+node which could not renew lease before ttl was passed is not leader even database is unreachable
+*/
+type LeaderElector struct {
+	name      string
+	nodeId    string
+	ttl       time.Duration
+	dbAdapter connection.DatabaseAdapterConnection
+	mutex     *sync.RWMutex
+	lease     *models.LeaderLease // lastest lease which was known, leader may be another node
+	expiredAt time.Time           // deadline of lease of this node by local clock
+	onElected []func()
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+func NewLeaderElector(name string, nodeId string, ttl time.Duration) *LeaderElector {
+	if ttl <= 0 {
+		ttl = default_leader_lease_ttl
+	}
+	return &LeaderElector{
+		name:      name,
+		nodeId:    nodeId,
+		ttl:       ttl,
+		mutex:     new(sync.RWMutex),
+		onElected: make([]func(), 0),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+func (e *LeaderElector) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		NodeId   string              `json:"node_id"`
+		IsLeader bool                `json:"is_leader"`
+		Leader   *models.LeaderLease `json:"leader"`
+	}
+	return json.Marshal(ptr{
+		NodeId:   e.nodeId,
+		IsLeader: e.IsLeader(),
+		Leader:   e.GetLeader(),
+	})
+}
+
+/* fn is called when node become leader */
+func (e *LeaderElector) OnElected(fn func()) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.onElected = append(e.onElected, fn)
+}
+
+/* first election is done before return, scheduler which is started after that know leader of node */
+func (e *LeaderElector) Start(dbAdapter connection.DatabaseAdapterConnection) {
+	e.dbAdapter = dbAdapter
+	e.elect()
+	go e.loop()
+}
+
+/* release lease for another node take over immediately */
+func (e *LeaderElector) Stop() {
+	close(e.stop)
+	<-e.stopped
+
+	isLeader := e.IsLeader()
+	e.mutex.Lock()
+	e.expiredAt = time.Time{}
+	e.mutex.Unlock()
+	if isLeader {
+		if err := e.dbAdapter.GetRepository().ReleaseLease(context.Background(), e.name, e.nodeId); err != nil {
+			log.Errorf("failed to release leader lease with error: %s", err.Error())
+		}
+	}
+}
+
+func (e *LeaderElector) loop() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.elect()
+		}
+	}
+}
+
+func (e *LeaderElector) elect() {
+	/* deadline is counted before request, lease on database is not expired before local deadline */
+	start := time.Now()
+	wasLeader := e.IsLeader()
+	repository := e.dbAdapter.GetRepository()
+	lease, err := repository.AcquireLease(context.Background(), e.name, e.nodeId, e.ttl)
+	if err != nil {
+		log.Errorf("failed to acquire leader lease with error: %s", err.Error())
+		return
+	}
+	if lease == nil {
+		if lease, err = repository.GetLease(context.Background(), e.name); err != nil {
+			log.Errorf("failed to get leader lease with error: %s", err.Error())
+			return
+		}
+	}
+
+	e.mutex.Lock()
+	e.lease = lease
+	e.expiredAt = time.Time{}
+	if lease != nil && lease.NodeId == e.nodeId {
+		e.expiredAt = start.Add(e.ttl)
+	}
+	onElected := append(make([]func(), 0, len(e.onElected)), e.onElected...)
+	e.mutex.Unlock()
+
+	switch isLeader := e.IsLeader(); {
+	case isLeader && !wasLeader:
+		log.Infof("node %s was elected as leader with fencing token %d", e.nodeId, lease.FencingToken)
+		for _, fn := range onElected {
+			fn()
+		}
+	case !isLeader && wasLeader:
+		log.Infof("node %s lost leader lease", e.nodeId)
+	}
+}
+
+func (e *LeaderElector) GetNodeId() string {
+	return e.nodeId
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return time.Now().Before(e.expiredAt)
+}
+
+/* lease of current leader, nil when leader is unknown */
+func (e *LeaderElector) GetLeader() *models.LeaderLease {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	if e.lease == nil {
+		return nil
+	}
+	lease := *e.lease
+	return &lease
+}
+
+/* lease of this node for fence write on database, nil when node is not leader */
+func (e *LeaderElector) GetFencingLease() *models.LeaderLease {
+	if !e.IsLeader() {
+		return nil
+	}
+	return e.GetLeader()
+}
+
+/* check lease and fencing token on database before write, node which was replaced by another leader is rejected */
+func (e *LeaderElector) Validate(ctx context.Context) (bool, error) {
+	if !e.IsLeader() {
+		return false, nil
+	}
+	lease := e.GetLeader()
+	return e.dbAdapter.GetRepository().CheckLease(ctx, e.name, e.nodeId, lease.FencingToken)
+}
@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/service/v1/schedule"
+)
+
+/* lease is taken over when it was expired, fencing token is increased when leader was changed */
+func (f *fakeRepository) AcquireLease(ctx context.Context, name string, nodeId string, ttl time.Duration) (*models.LeaderLease, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	lease, ok := f.leases[name]
+	switch {
+	case !ok:
+		lease = models.LeaderLease{Name: name, NodeId: nodeId, FencingToken: 1}
+	case lease.NodeId == nodeId:
+	case lease.ExpiredAt.Before(time.Now()):
+		lease.NodeId = nodeId
+		lease.FencingToken++
+	default:
+		return nil, nil
+	}
+	lease.ExpiredAt = time.Now().Add(ttl)
+	f.leases[name] = lease
+	return &lease, nil
+}
+
+func (f *fakeRepository) GetLease(ctx context.Context, name string) (*models.LeaderLease, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	lease, ok := f.leases[name]
+	if !ok {
+		return nil, nil
+	}
+	return &lease, nil
+}
+
+func (f *fakeRepository) CheckLease(ctx context.Context, name string, nodeId string, fencingToken int64) (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	lease, ok := f.leases[name]
+	return ok && lease.NodeId == nodeId && lease.FencingToken == fencingToken && lease.ExpiredAt.After(time.Now()), nil
+}
+
+func (f *fakeRepository) ReleaseLease(ctx context.Context, name string, nodeId string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if lease, ok := f.leases[name]; ok && lease.NodeId == nodeId {
+		lease.ExpiredAt = time.Now()
+		f.leases[name] = lease
+	}
+	return nil
+}
+
+func (f *fakeRepository) expireLease(name string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	lease := f.leases[name]
+	lease.ExpiredAt = time.Now().Add(-time.Second)
+	f.leases[name] = lease
+}
+
+/* database which respond slowly or fail on acquire lease */
+type slowLeaseRepository struct {
+	*fakeRepository
+	delay time.Duration
+	err   error
+}
+
+func (r slowLeaseRepository) AcquireLease(ctx context.Context, name string, nodeId string, ttl time.Duration) (*models.LeaderLease, error) {
+	time.Sleep(r.delay)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.fakeRepository.AcquireLease(ctx, name, nodeId, ttl)
+}
+
+type slowLeaseAdapter struct {
+	fakeAdapter
+	repository slowLeaseRepository
+}
+
+func (a slowLeaseAdapter) GetRepository() schedule.Repository { return a.repository }
+
+func newTestElector(nodeId string, ttl time.Duration, repository *fakeRepository) *LeaderElector {
+	elector := NewLeaderElector("test", nodeId, ttl)
+	elector.dbAdapter = fakeAdapter{repository: repository}
+	return elector
+}
+
+func TestLeaderElection(t *testing.T) {
+	repository := newFakeRepository()
+	a, b := newTestElector("a", time.Minute, repository), newTestElector("b", time.Minute, repository)
+	var elected int
+	a.OnElected(func() { elected++ })
+
+	a.elect()
+	b.elect()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected a was leader, got a %v and b %v", a.IsLeader(), b.IsLeader())
+	}
+	if leader := b.GetLeader(); leader == nil || leader.NodeId != "a" || leader.FencingToken != 1 {
+		t.Fatalf("expected follower know leader a, got %+v", leader)
+	}
+	a.elect()
+	if elected != 1 || a.GetFencingLease().FencingToken != 1 {
+		t.Fatalf("expected renew keep leader without elected again, got elected %d", elected)
+	}
+
+	/* a could not renew lease before it was expired, another node take over with new fencing token */
+	repository.expireLease("test")
+	b.elect()
+	a.elect()
+	if a.IsLeader() || !b.IsLeader() {
+		t.Fatalf("expected b took over leader, got a %v and b %v", a.IsLeader(), b.IsLeader())
+	}
+	if lease := b.GetFencingLease(); lease.FencingToken != 2 {
+		t.Fatalf("expected fencing token 2, got %d", lease.FencingToken)
+	}
+	if a.GetFencingLease() != nil {
+		t.Fatal("expected follower has no fencing lease")
+	}
+}
+
+func TestLeaderLocalDeadline(t *testing.T) {
+	ttl := 200 * time.Millisecond
+	repository := newFakeRepository()
+	elector := NewLeaderElector("test", "a", ttl)
+	elector.dbAdapter = slowLeaseAdapter{repository: slowLeaseRepository{fakeRepository: repository, delay: 100 * time.Millisecond}}
+
+	/* deadline is counted from before request, node stop being leader before lease on database was expired */
+	elector.elect()
+	if !elector.IsLeader() {
+		t.Fatal("expected node was leader")
+	}
+	time.Sleep(120 * time.Millisecond)
+	if elector.IsLeader() {
+		t.Fatal("expected local deadline was passed")
+	}
+	if ok, _ := repository.CheckLease(context.Background(), "test", "a", 1); !ok {
+		t.Fatal("expected lease on database was not expired yet")
+	}
+}
+
+func TestLeaderUnreachableDatabase(t *testing.T) {
+	repository := newFakeRepository()
+	elector := newTestElector("a", 100*time.Millisecond, repository)
+	elector.elect()
+
+	/* leader is kept until local deadline when lease could not be renewed */
+	elector.dbAdapter = slowLeaseAdapter{repository: slowLeaseRepository{fakeRepository: repository, err: errors.New("connection refused")}}
+	elector.elect()
+	if !elector.IsLeader() {
+		t.Fatal("expected node was leader until local deadline")
+	}
+	time.Sleep(120 * time.Millisecond)
+	elector.elect()
+	if elector.IsLeader() {
+		t.Fatal("expected node lost leader after local deadline")
+	}
+}
+
+func TestLeaderValidate(t *testing.T) {
+	repository := newFakeRepository()
+	a, b := newTestElector("a", time.Minute, repository), newTestElector("b", time.Minute, repository)
+	a.elect()
+	if ok, err := a.Validate(context.Background()); err != nil || !ok {
+		t.Fatalf("expected leader was valid, got %v %v", ok, err)
+	}
+	if ok, _ := b.Validate(context.Background()); ok {
+		t.Fatal("expected follower was rejected")
+	}
+
+	/* a still believe it is leader but lease was taken over by b, stale fencing token is rejected */
+	repository.expireLease("test")
+	b.elect()
+	if !a.IsLeader() {
+		t.Fatal("expected a still believe it is leader")
+	}
+	if ok, _ := a.Validate(context.Background()); ok {
+		t.Fatal("expected stale fencing token was rejected")
+	}
+	if ok, _ := b.Validate(context.Background()); !ok {
+		t.Fatal("expected new leader was valid")
+	}
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+/*
+expand mapped task by value of upstream and run every instance concurrently by max active,
+status of mapped task is first status which was not success by order of instance
+*/
+func (jr *jobRunner) executeMappedTask(mapped *task.TaskMapped) (interface{}, constants.JobStatus) {
+	taskResult := taskResult{
+		task:      mapped,
+		status:    constants.JOB_STATUS_RUNNING,
+		attempt:   jr.attempts[mapped.GetId()] + 1,
+		startDate: time.Now(),
+	}
+	jr.saveJobTask(mapped, taskResult, nil, nil)
+
+	upstreamValue, _ := jr.taskValue.Load(mapped.GetUpstream())
+	items, err := mapped.GetItems(upstreamValue)
+	if err != nil {
+		ti := time.Now()
+		taskResult.status = constants.JOB_STATUS_FAILED
+		taskResult.endDatetime = &ti
+		exception := jr.fail(mapped, taskResult.status, err)
+		jr.addTaskResult(taskResult)
+		jr.saveJobTask(mapped, taskResult, nil, exception)
+		return nil, taskResult.status
+	}
+
+	instances := mapped.Expand(items)
+	var values = make([]interface{}, len(instances))
+	var statuses = make([]constants.JobStatus, len(instances))
+	var limit chan struct{}
+	if mapped.GetMaxActive() > 0 {
+		limit = make(chan struct{}, mapped.GetMaxActive())
+	}
+	var wg sync.WaitGroup
+	for index, instance := range instances {
+		if limit != nil {
+			limit <- struct{}{}
+		}
+		wg.Add(1)
+		go func(index int, instance task.Execution) {
+			defer wg.Done()
+			defer func() {
+				if limit != nil {
+					<-limit
+				}
+			}()
+			values[index], statuses[index] = jr.executeTask(instance)
+		}(index, instance)
+	}
+	wg.Wait()
+
+	ti := time.Now()
+	taskResult.status = constants.JOB_STATUS_SUCCESS
+	taskResult.endDatetime = &ti
+	var exception Exception
+	for index, status := range statuses {
+		if status != constants.JOB_STATUS_SUCCESS {
+			taskResult.status = status
+			exception = newRunnerException(fmt.Errorf("instance %s was finished with status %s", instances[index].GetName(), status), false)
+			break
+		}
+	}
+	jr.addTaskResult(taskResult)
+	if taskResult.status != constants.JOB_STATUS_SUCCESS {
+		jr.saveJobTask(mapped, taskResult, nil, exception)
+		return nil, taskResult.status
+	}
+	jr.saveJobTask(mapped, taskResult, values, nil)
+	jr.taskValue.Store(mapped.GetId(), values)
+	return values, taskResult.status
+}
@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func newValueTask(name string, value interface{}) task.Execution {
+	return task.NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		return value, nil
+	}))
+}
+
+func TestMappedTask(t *testing.T) {
+	boom := errors.New("boom")
+	cases := []struct {
+		name     string
+		value    interface{}
+		job      constants.JobStatus
+		mapped   constants.JobStatus
+		after    constants.JobStatus
+		expected []interface{}
+	}{
+		{name: "list", value: []int{1, 2, 3}, job: constants.JOB_STATUS_SUCCESS, mapped: constants.JOB_STATUS_SUCCESS, after: constants.JOB_STATUS_SUCCESS, expected: []interface{}{2, 4, 6}},
+		{name: "empty list", value: []int{}, job: constants.JOB_STATUS_SUCCESS, mapped: constants.JOB_STATUS_SUCCESS, after: constants.JOB_STATUS_SUCCESS, expected: []interface{}{}},
+		{name: "nil is empty list", value: nil, job: constants.JOB_STATUS_SUCCESS, mapped: constants.JOB_STATUS_SUCCESS, after: constants.JOB_STATUS_SUCCESS, expected: []interface{}{}},
+		{name: "value is not list", value: "a", job: constants.JOB_STATUS_FAILED, mapped: constants.JOB_STATUS_FAILED, after: constants.JOB_STATUS_SKIPPED},
+		{name: "instance was failed", value: []int{1, -1}, job: constants.JOB_STATUS_FAILED, mapped: constants.JOB_STATUS_FAILED, after: constants.JOB_STATUS_SKIPPED},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var values interface{}
+			upstream := newValueTask("extract", tc.value)
+			mapped := task.NewTaskMapped("double", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				item := ctx.Value(constants.MAP_ITEM_KEY).(int)
+				if item < 0 {
+					return nil, boom
+				}
+				return item * 2, nil
+			}), task.MappedConfig{Upstream: "extract"})
+			after := task.NewTask("after", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				values, _ = ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(interface {
+					GetTaskValue(taskId string) (interface{}, bool)
+				}).GetTaskValue("double")
+				return nil, nil
+			}))
+			job := NewJob(nil)
+			job.AddTask(upstream, mapped, after)
+
+			repository := runTestJob(t, job)
+			if repository.job.Status != tc.job {
+				t.Fatalf("expected job %s, got %s", tc.job, repository.job.Status)
+			}
+			if status := repository.tasks["double"].Status; status != tc.mapped {
+				t.Fatalf("expected mapped task %s, got %s", tc.mapped, status)
+			}
+			if status := repository.tasks["after"].Status; status != tc.after {
+				t.Fatalf("expected downstream %s, got %s", tc.after, status)
+			}
+			if tc.expected != nil && !reflect.DeepEqual(values, tc.expected) {
+				t.Fatalf("expected value %v, got %v", tc.expected, values)
+			}
+			if list, ok := tc.value.([]int); ok && len(list) > 0 {
+				if _, ok := repository.tasks["double[0]"]; !ok {
+					t.Fatal("expected instance saved by index suffix")
+				}
+			}
+		})
+	}
+}
+
+func TestMappedTaskMaxActive(t *testing.T) {
+	var running, peak int32
+	mapped := task.NewTaskMapped("mapped", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			last := atomic.LoadInt32(&peak)
+			if current <= last || atomic.CompareAndSwapInt32(&peak, last, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	}), task.MappedConfig{Upstream: "extract", MaxActive: 2})
+	job := NewJob(nil)
+	job.AddTask(newValueTask("extract", []int{1, 2, 3, 4, 5}), mapped)
+
+	repository := runTestJob(t, job)
+	if repository.job.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected job success, got %s", repository.job.Status)
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 instances run concurrently, got %d", peak)
+	}
+}
@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	default_job_heartbeat_timeout = time.Minute
+)
+
+/*
+keep heartbeat of running job on node and detect orphaned job which node stopped heartbeat longer than timeout,
+orphaned job is failed or run again by orphan policy of scheduler
+*/
+type OrphanDetector struct {
+	nodeId    string
+	registry  *Registry
+	dbAdapter connection.DatabaseAdapterConnection
+	timeout   time.Duration
+	startedAt time.Time // job of node which was updated before started is left by previous process
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+/* detector must be created before any job of node was run */
+func NewOrphanDetector(nodeId string, registry *Registry, timeout time.Duration) *OrphanDetector {
+	if timeout <= 0 {
+		timeout = default_job_heartbeat_timeout
+	}
+	return &OrphanDetector{
+		nodeId:    nodeId,
+		registry:  registry,
+		timeout:   timeout,
+		startedAt: time.Now(),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+/* recover job which was left by previous process of node, scheduler in registry must be started before */
+func (d *OrphanDetector) Start(dbAdapter connection.DatabaseAdapterConnection) {
+	d.dbAdapter = dbAdapter
+	jobs, err := dbAdapter.GetRepository().ClaimNodeJobs(context.Background(), d.nodeId, d.startedAt)
+	if err != nil {
+		log.Errorf("failed to claim job of previous process with error: %s", err.Error())
+	}
+	d.recoverJobs(jobs)
+	d.detect()
+	go d.loop()
+}
+
+func (d *OrphanDetector) Stop() {
+	close(d.stop)
+	<-d.stopped
+}
+
+func (d *OrphanDetector) loop() {
+	defer close(d.stopped)
+	ticker := time.NewTicker(d.timeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			/* only job which is tracked by registry is alive, row of node which is not tracked is left to detect */
+			if err := d.dbAdapter.GetRepository().HeartbeatJobs(context.Background(), d.nodeId, d.registry.runningJobIds()); err != nil {
+				log.Errorf("failed to heartbeat job with error: %s", err.Error())
+			}
+			d.detect()
+		}
+	}
+}
+
+func (d *OrphanDetector) detect() {
+	jobs, err := d.dbAdapter.GetRepository().ClaimOrphanJobs(context.Background(), d.nodeId, d.timeout)
+	if err != nil {
+		log.Errorf("failed to claim orphaned job with error: %s", err.Error())
+		return
+	}
+	d.recoverJobs(jobs)
+}
+
+func (d *OrphanDetector) recoverJobs(jobs []*models.Job) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *models.Job) {
+			defer wg.Done()
+			if err := d.recoverJob(context.Background(), job); err != nil {
+				log.Errorf("failed to recover orphaned job %s with error: %s", job.JobId, err.Error())
+			}
+		}(job)
+	}
+	wg.Wait()
+}
+
+/* running task of orphaned job is failed, job is run again by resume mode when scheduler on node has requeue policy */
+func (d *OrphanDetector) recoverJob(ctx context.Context, job *models.Job) error {
+	repository := d.dbAdapter.GetRepository()
+	if err := repository.CancelTasksByJobId(ctx, job.JobId); err != nil {
+		return err
+	}
+	if err := repository.FailRunningJobTasks(ctx, job.JobId, constants.ORPHAN_EXCEPTION); err != nil {
+		return err
+	}
+
+	schedulerInstance := d.registry.Get(job.SchedulerName)
+	if schedulerInstance != nil && schedulerInstance.config.getOrphanPolicy() == constants.ORPHAN_POLICY_REQUEUE {
+		jobTasks, err := repository.GetOneJobTaskByJobId(ctx, job.JobId)
+		if err != nil {
+			return err
+		}
+		var triggerConfig = new(sync.Map)
+		trigger, err := repository.GetOneTriggerByJobId(ctx, job.JobId)
+		if err != nil {
+			return err
+		}
+		if trigger != nil {
+			triggerConfig = trigger.GetConfigMutex()
+		}
+		log.Infof("requeue orphaned job %s of scheduler %s", job.JobId, job.SchedulerName)
+		return schedulerInstance.Rerun(ctx, job, jobTasks, triggerConfig, constants.RERUN_MODE_RESUME)
+	}
+
+	ti := time.Now()
+	job.Status = constants.JOB_STATUS_FAILED
+	job.Exception = constants.ORPHAN_EXCEPTION
+	job.EndDatetime = &ti
+	job.UpdatedAt = ti
+	log.Infof("fail orphaned job %s of scheduler %s", job.JobId, job.SchedulerName)
+	return repository.UpsertJob(ctx, job)
+}
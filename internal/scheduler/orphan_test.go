@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+)
+
+func (f *fakeRepository) GetOneJobTaskByJobId(ctx context.Context, jobId string) ([]*models.JobTask, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var jobTasks = make([]*models.JobTask, 0)
+	for _, jobTask := range f.tasks {
+		if jobTask.JobId == jobId {
+			copied := jobTask
+			jobTasks = append(jobTasks, &copied)
+		}
+	}
+	return jobTasks, nil
+}
+
+func (f *fakeRepository) GetOneTriggerByJobId(ctx context.Context, jobId string) (*models.Trigger, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, trigger := range f.triggers {
+		if trigger.JobId == jobId {
+			copied := trigger
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) CancelTasksByJobId(ctx context.Context, jobId string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, item := range f.queue {
+		if item.JobId == jobId && (item.Status == constants.QUEUE_STATUS_QUEUED || item.Status == constants.QUEUE_STATUS_RUNNING) {
+			item.Status = constants.QUEUE_STATUS_CANCELLED
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) FailRunningJobTasks(ctx context.Context, jobId string, exception string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for taskId, jobTask := range f.tasks {
+		switch jobTask.Status {
+		case constants.JOB_STATUS_WAITING, constants.JOB_STATUS_RUNNING, constants.JOB_STATUS_UP_FOR_RETRY, constants.JOB_STATUS_UP_FOR_RESCHEDULE:
+			if jobTask.JobId == jobId {
+				jobTask.Status, jobTask.TaskException = constants.JOB_STATUS_FAILED, exception
+				f.tasks[taskId] = jobTask
+			}
+		}
+	}
+	return nil
+}
+
+func isUnfinishedJob(job models.Job) bool {
+	return job.Status == constants.JOB_STATUS_WAITING || job.Status == constants.JOB_STATUS_RUNNING
+}
+
+func (f *fakeRepository) ClaimNodeJobs(ctx context.Context, nodeId string, before time.Time) ([]*models.Job, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var jobs = make([]*models.Job, 0)
+	for jobId, job := range f.jobs {
+		if job.NodeId == nodeId && isUnfinishedJob(job) && job.UpdatedAt.Before(before) {
+			ti := time.Now()
+			job.HeartbeatAt = &ti
+			f.jobs[jobId] = job
+			copied := job
+			jobs = append(jobs, &copied)
+		}
+	}
+	return jobs, nil
+}
+
+func (f *fakeRepository) ClaimOrphanJobs(ctx context.Context, nodeId string, timeout time.Duration) ([]*models.Job, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var jobs = make([]*models.Job, 0)
+	for jobId, job := range f.jobs {
+		heartbeatAt := job.UpdatedAt
+		if job.HeartbeatAt != nil {
+			heartbeatAt = *job.HeartbeatAt
+		}
+		if isUnfinishedJob(job) && time.Since(heartbeatAt) > timeout {
+			ti := time.Now()
+			job.NodeId, job.HeartbeatAt = nodeId, &ti
+			f.jobs[jobId] = job
+			copied := job
+			jobs = append(jobs, &copied)
+		}
+	}
+	return jobs, nil
+}
+
+func (f *fakeRepository) HeartbeatJobs(ctx context.Context, nodeId string, jobIds []string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, jobId := range jobIds {
+		if job, ok := f.jobs[jobId]; ok && job.NodeId == nodeId && isUnfinishedJob(job) {
+			ti := time.Now()
+			job.HeartbeatAt = &ti
+			f.jobs[jobId] = job
+		}
+	}
+	return nil
+}
+
+/* registry with scheduler which task count call, detector is not started */
+func newOrphanTestDetector(t *testing.T, policy constants.OrphanPolicy, calls map[string]*int32) (*OrphanDetector, *SchedulerInstance, *fakeRepository) {
+	job := NewJob(nil)
+	for _, name := range []string{"a", "b"} {
+		count := calls[name]
+		job.AddTask(newResultTaskFunc(name, func() { atomic.AddInt32(count, 1) }))
+	}
+	config := NewDefaultSchedulerConfig()
+	config.OrphanPolicy = policy
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	if err := registry.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	if err := registry.Start(fakeAdapter{repository: repository}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(registry.Stop)
+	detector := NewOrphanDetector("node", registry, time.Minute)
+	detector.dbAdapter = fakeAdapter{repository: repository}
+	return detector, s, repository
+}
+
+/* job which task a was success and task b was running when node was stopped */
+func saveOrphanedJob(repository *fakeRepository, schedulerName string, jobId string, nodeId string, updatedAt time.Time) *models.Job {
+	startedAt := updatedAt.Add(-time.Minute)
+	job := &models.Job{SchedulerName: schedulerName, JobId: jobId, Status: constants.JOB_STATUS_RUNNING, StartDateTime: &startedAt, NodeId: nodeId, UpdatedAt: updatedAt}
+	repository.UpsertJob(context.Background(), job)
+	repository.UpsertJobTask(context.Background(), &models.JobTask{SchedulerName: schedulerName, JobId: jobId, TaskId: "a", TaskName: "a", Status: constants.JOB_STATUS_SUCCESS, Attempt: 1, TaskValue: `"a"`})
+	repository.UpsertJobTask(context.Background(), &models.JobTask{SchedulerName: schedulerName, JobId: jobId, TaskId: "b", TaskName: "b", Status: constants.JOB_STATUS_RUNNING, Attempt: 1})
+	repository.EnqueueTask(context.Background(), &models.TaskQueue{SchedulerName: schedulerName, JobId: jobId, TaskId: "b", Attempt: 1})
+	return job
+}
+
+func TestRecoverJobFail(t *testing.T) {
+	calls := map[string]*int32{"a": new(int32), "b": new(int32)}
+	detector, s, repository := newOrphanTestDetector(t, constants.ORPHAN_POLICY_FAIL, calls)
+	job := saveOrphanedJob(repository, s.name, "job", "dead", time.Now().Add(-time.Hour))
+
+	if err := detector.recoverJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := repository.GetOneJob(context.Background(), "job")
+	if saved.Status != constants.JOB_STATUS_FAILED || saved.Exception != constants.ORPHAN_EXCEPTION {
+		t.Fatalf("expected job failed by orphan, got %s %q", saved.Status, saved.Exception)
+	}
+	if b := repository.tasks["b"]; b.Status != constants.JOB_STATUS_FAILED || b.TaskException != constants.ORPHAN_EXCEPTION {
+		t.Fatalf("expected running task failed by orphan, got %s %q", b.Status, b.TaskException)
+	}
+	if a := repository.tasks["a"]; a.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected finished task was kept, got %s", a.Status)
+	}
+	if item := repository.getQueue(1); item.Status != constants.QUEUE_STATUS_CANCELLED {
+		t.Fatalf("expected task on queue was cancelled, got %s", item.Status)
+	}
+	if *calls["a"] != 0 || *calls["b"] != 0 {
+		t.Fatal("expected job was not run again")
+	}
+}
+
+func TestRecoverJobRequeue(t *testing.T) {
+	calls := map[string]*int32{"a": new(int32), "b": new(int32)}
+	detector, s, repository := newOrphanTestDetector(t, constants.ORPHAN_POLICY_REQUEUE, calls)
+	job := saveOrphanedJob(repository, s.name, "job", "dead", time.Now().Add(-time.Hour))
+	stale := *job
+
+	if err := detector.recoverJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	/* job which was requeued already is not run again by another recover */
+	if err := detector.recoverJob(context.Background(), &stale); err == nil || err.Error() != constants.ERROR_JOB_CLAIMED {
+		t.Fatalf("expected job was claimed error, got %v", err)
+	}
+	waitJobStatus(t, repository, "job", constants.JOB_STATUS_SUCCESS)
+
+	/* resume from task which was not success */
+	if atomic.LoadInt32(calls["a"]) != 0 || atomic.LoadInt32(calls["b"]) != 1 {
+		t.Fatalf("expected only b was run again, got a %d and b %d", *calls["a"], *calls["b"])
+	}
+	if b := repository.tasks["b"]; b.Status != constants.JOB_STATUS_SUCCESS || b.Attempt != 2 {
+		t.Fatalf("expected b success on attempt 2, got %s on attempt %d", b.Status, b.Attempt)
+	}
+}
+
+func TestOrphanDetectorStart(t *testing.T) {
+	calls := map[string]*int32{"a": new(int32), "b": new(int32)}
+	detector, s, repository := newOrphanTestDetector(t, constants.ORPHAN_POLICY_FAIL, calls)
+	before := detector.startedAt.Add(-time.Second)
+	saveOrphanedJob(repository, s.name, "previous", "node", before)
+	/* job of another node which is still alive, and job which was run by this process */
+	alive := saveOrphanedJob(repository, s.name, "alive", "other", time.Now())
+	ti := time.Now()
+	alive.HeartbeatAt = &ti
+	repository.UpsertJob(context.Background(), alive)
+	saveOrphanedJob(repository, s.name, "current", "node", time.Now().Add(time.Second))
+
+	/* job of previous process is recovered on start without waiting for timeout */
+	detector.Start(fakeAdapter{repository: repository})
+	detector.Stop()
+	expected := map[string]constants.JobStatus{
+		"previous": constants.JOB_STATUS_FAILED,
+		"alive":    constants.JOB_STATUS_RUNNING,
+		"current":  constants.JOB_STATUS_RUNNING,
+	}
+	for jobId, status := range expected {
+		if job, _ := repository.GetOneJob(context.Background(), jobId); job.Status != status {
+			t.Fatalf("expected job %s %s, got %s", jobId, status, job.Status)
+		}
+	}
+}
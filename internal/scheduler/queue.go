@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	default_queue_poll_interval = time.Second
+)
+
+/* state of job which worker restore before call task */
+type queuePayload struct {
+	ExecuteDatetime time.Time              `json:"execute_datetime"`
+	TriggerConfig   map[string]interface{} `json:"trigger_config"`
+	Parameter       string                 `json:"parameter"`
+	TaskValues      map[string]string      `json:"task_values"` // serialized value of task by id
+}
+
+func getQueuePollInterval() time.Duration {
+	if constants.ENV_WORKER_POLL_INTERVAL <= 0 {
+		return default_queue_poll_interval
+	}
+	return constants.ENV_WORKER_POLL_INTERVAL
+}
+
+/* task in job and branch pipeline by id, instance of mapped task which is created on runtime is not found */
+func findTask(tasks []task.Execution, taskId string) task.Execution {
+	for _, execution := range tasks {
+		if execution.GetId() == taskId {
+			return execution
+		}
+		if branch, ok := execution.(*task.TaskBranch); ok {
+			for _, pipeline := range branch.GetPipelines() {
+				if found := findTask(pipeline.GetTasks(), taskId); found != nil {
+					return found
+				}
+			}
+		}
+	}
+	return nil
+}
+
+/* on queue mode task is called by worker, sensor and instance of mapped task are called on node which run job */
+func (jr *jobRunner) isQueued(taskExecution task.Execution) bool {
+	if jr.config.getExecutionMode() != constants.EXECUTION_MODE_QUEUE {
+		return false
+	}
+	if _, ok := taskExecution.(*task.TaskSensor); ok {
+		return false
+	}
+	return findTask(jr.tasks, taskExecution.GetId()) == taskExecution
+}
+
+func (jr *jobRunner) encodePayload() (string, error) {
+	payload := queuePayload{
+		ExecuteDatetime: jr.executeDatetime,
+		TriggerConfig:   constants.PARSE_SYNC_MAP_TO_MAP(jr.triggerConfig),
+		Parameter:       jr.encodeParameter(),
+		TaskValues:      make(map[string]string),
+	}
+	jr.taskValue.Range(func(key, value interface{}) bool {
+		payload.TaskValues[key.(string)] = jr.encodeTaskValue(key.(string), value)
+		return true
+	})
+	bu, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(bu), nil
+}
+
+/* enqueue task and wait until it was finished by worker, task on queue is cancelled when context was done */
+func (jr *jobRunner) callQueue(ctx context.Context, taskExecution task.Execution, attempt int) (interface{}, error) {
+	payload, err := jr.encodePayload()
+	if err != nil {
+		return nil, err
+	}
+	item := &models.TaskQueue{
+		SchedulerName: jr.schedulerName,
+		JobId:         jr.id,
+		TaskId:        taskExecution.GetId(),
+		Attempt:       attempt,
+		Status:        constants.QUEUE_STATUS_QUEUED,
+		Payload:       payload,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	repository := jr.dbAdapter.GetRepository()
+	if err := repository.EnqueueTask(ctx, item); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(getQueuePollInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := repository.CancelTask(context.Background(), item.Id); err != nil {
+				log.Errorf("failed to cancel task %s of job %s on queue with error: %s", item.TaskId, item.JobId, err.Error())
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		result, err := repository.GetTaskQueue(ctx, item.Id)
+		if err != nil {
+			/* keep waiting, worker is still running task */
+			log.Errorf("failed to get task %s of job %s on queue with error: %s", item.TaskId, item.JobId, err.Error())
+			continue
+		}
+		if result == nil {
+			return nil, fmt.Errorf("task %s was removed from queue", item.TaskId)
+		}
+		switch result.Status {
+		case constants.QUEUE_STATUS_SUCCESS:
+			jr.restoreParameter(result.Parameter)
+			value := deserializeValue(jr.config, result.Result)
+			if branch, ok := taskExecution.(*task.TaskBranch); ok {
+				return branch.SelectPipelines(value)
+			}
+			return value, nil
+		case constants.QUEUE_STATUS_FAILED:
+			jr.restoreParameter(result.Parameter)
+			if result.TaskException == context.DeadlineExceeded.Error() {
+				return nil, context.DeadlineExceeded
+			}
+			return nil, errors.New(result.TaskException)
+		case constants.QUEUE_STATUS_CANCELLED:
+			return nil, fmt.Errorf("task %s was cancelled on queue", item.TaskId)
+		}
+	}
+}
+
+/* runner on worker which restore state of job from payload */
+func newQueueRunner(ctx context.Context, ji *JobInstance, item *models.TaskQueue, payload queuePayload) *jobRunner {
+	triggerConfig := new(sync.Map)
+	for k, v := range payload.TriggerConfig {
+		triggerConfig.Store(k, v)
+	}
+	runner := newJobRunner(ctx, ji, triggerConfig, &payload.ExecuteDatetime)
+	runner.id = item.JobId
+	runner.status = constants.JOB_STATUS_RUNNING
+	runner.restoreParameter(payload.Parameter)
+	for taskId, data := range payload.TaskValues {
+		value := deserializeValue(runner.config, data)
+		if branch, ok := findTask(ji.tasks, taskId).(*task.TaskBranch); ok {
+			value, _ = branch.SelectPipelines(value)
+		}
+		runner.taskValue.Store(taskId, value)
+	}
+
+	runner.ctx = context.WithValue(ctx, constants.JOB_RUNNER_INSTANCE_KEY, runner.getRunnerInterface())
+	runner.jobCtx = runner.ctx
+	runner.logjob = &models.Job{
+		SchedulerName: ji.scheduler.name,
+		JobId:         runner.id,
+		Status:        runner.status,
+	}
+	return runner
+}
@@ -0,0 +1,261 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+/* queue of fake repository, task is unique by job, task and attempt */
+func (f *fakeRepository) EnqueueTask(ctx context.Context, item *models.TaskQueue) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.queue == nil {
+		f.queue = map[int64]*models.TaskQueue{}
+	}
+	for id, existed := range f.queue {
+		if existed.JobId == item.JobId && existed.TaskId == item.TaskId && existed.Attempt == item.Attempt {
+			item.Id = id
+		}
+	}
+	if item.Id == 0 {
+		item.Id = int64(len(f.queue) + 1)
+	}
+	copied := *item
+	copied.Status = constants.QUEUE_STATUS_QUEUED
+	copied.NodeId, copied.HeartbeatAt = nil, nil
+	f.queue[item.Id] = &copied
+	return nil
+}
+
+func (f *fakeRepository) ClaimTasks(ctx context.Context, nodeId string, schedulerNames []string, limit int) ([]*models.TaskQueue, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var ids = make([]int64, 0)
+	for id, item := range f.queue {
+		if item.Status == constants.QUEUE_STATUS_QUEUED && strings.Contains(","+strings.Join(schedulerNames, ",")+",", ","+item.SchedulerName+",") {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var items = make([]*models.TaskQueue, 0)
+	for _, id := range ids {
+		if len(items) >= limit {
+			break
+		}
+		ti := time.Now()
+		item := f.queue[id]
+		item.Status, item.NodeId, item.HeartbeatAt = constants.QUEUE_STATUS_RUNNING, &nodeId, &ti
+		item.ClaimCount++
+		copied := *item
+		items = append(items, &copied)
+	}
+	return items, nil
+}
+
+func (f *fakeRepository) HeartbeatTasks(ctx context.Context, nodeId string, ids []int64) ([]int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var owned = make([]int64, 0)
+	for _, id := range ids {
+		if item, ok := f.queue[id]; ok && item.Status == constants.QUEUE_STATUS_RUNNING && item.NodeId != nil && *item.NodeId == nodeId {
+			ti := time.Now()
+			item.HeartbeatAt = &ti
+			owned = append(owned, id)
+		}
+	}
+	return owned, nil
+}
+
+func (f *fakeRepository) FinishTask(ctx context.Context, item *models.TaskQueue) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	existed, ok := f.queue[item.Id]
+	if !ok || existed.Status != constants.QUEUE_STATUS_RUNNING || existed.NodeId == nil || item.NodeId == nil || *existed.NodeId != *item.NodeId {
+		return nil
+	}
+	existed.Status, existed.Result, existed.Parameter = item.Status, item.Result, item.Parameter
+	existed.TaskException, existed.StackTrace = item.TaskException, item.StackTrace
+	return nil
+}
+
+func (f *fakeRepository) CancelTask(ctx context.Context, id int64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if item, ok := f.queue[id]; ok && (item.Status == constants.QUEUE_STATUS_QUEUED || item.Status == constants.QUEUE_STATUS_RUNNING) {
+		item.Status = constants.QUEUE_STATUS_CANCELLED
+	}
+	return nil
+}
+
+func (f *fakeRepository) GetTaskQueue(ctx context.Context, id int64) (*models.TaskQueue, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	item, ok := f.queue[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (f *fakeRepository) RequeueStaleTasks(ctx context.Context, timeout time.Duration) (int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var count int64
+	for _, item := range f.queue {
+		if item.Status == constants.QUEUE_STATUS_RUNNING && item.HeartbeatAt != nil && time.Since(*item.HeartbeatAt) > timeout {
+			item.Status, item.NodeId, item.HeartbeatAt = constants.QUEUE_STATUS_QUEUED, nil, nil
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeRepository) getQueue(id int64) models.TaskQueue {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return *f.queue[id]
+}
+
+/* scheduler on queue mode and worker of registry which poll quickly */
+func newQueueTestWorker(t *testing.T, job *JobInstance) (*SchedulerInstance, *Worker, *fakeRepository) {
+	pollInterval := constants.ENV_WORKER_POLL_INTERVAL
+	constants.ENV_WORKER_POLL_INTERVAL = 5 * time.Millisecond
+	t.Cleanup(func() { constants.ENV_WORKER_POLL_INTERVAL = pollInterval })
+
+	config := NewDefaultSchedulerConfig()
+	config.ExecutionMode = constants.EXECUTION_MODE_QUEUE
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	if err := registry.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	adapter := fakeAdapter{repository: repository}
+	if err := registry.Start(adapter); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(registry.Stop)
+	worker := NewWorker("worker", registry, 2, 5*time.Millisecond, 300*time.Millisecond)
+	worker.Start(adapter)
+	t.Cleanup(func() { worker.Shutdown(context.Background()) })
+	return s, worker, repository
+}
+
+func TestQueueRun(t *testing.T) {
+	extract := task.NewTask("extract", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(interface{ GetParameter() *sync.Map }).GetParameter().Store("source", "a")
+		return []interface{}{"x", "y"}, nil
+	}))
+	load := task.NewTask("load", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		runner := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(interface {
+			GetTaskValue(taskId string) (interface{}, bool)
+			GetParameter() *sync.Map
+		})
+		value, _ := runner.GetTaskValue("extract")
+		source, _ := runner.GetParameter().Load("source")
+		return []interface{}{value, source}, nil
+	}))
+	job := NewJob(nil)
+	job.AddTask(extract, load)
+	s, _, repository := newQueueTestWorker(t, job)
+
+	s.Run(newTestTrigger(s.name, "00000000-0000-0000-0000-000000000001"))
+	if repository.job.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected job success, got %s", repository.job.Status)
+	}
+	/* value and parameter are sent between node and worker by database */
+	if value := repository.tasks["load"].TaskValue; value != `[["x","y"],"a"]` {
+		t.Fatalf("unexpected value of load %s", value)
+	}
+	for _, id := range []int64{1, 2} {
+		item := repository.getQueue(id)
+		if item.Status != constants.QUEUE_STATUS_SUCCESS || item.ClaimCount != 1 || *item.NodeId != "worker" {
+			t.Fatalf("expected task was finished by worker, got %+v", item)
+		}
+	}
+}
+
+/* enqueue task which was claimed by worker which stopped heartbeat */
+func enqueueStaleTask(t *testing.T, repository *fakeRepository, schedulerName string, taskId string) int64 {
+	item := &models.TaskQueue{SchedulerName: schedulerName, JobId: "job", TaskId: taskId, Attempt: 1, Payload: "{}"}
+	if err := repository.EnqueueTask(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	dead, heartbeatAt := "dead", time.Now().Add(-time.Hour)
+	stale := repository.queue[item.Id]
+	stale.Status, stale.NodeId, stale.HeartbeatAt, stale.ClaimCount = constants.QUEUE_STATUS_RUNNING, &dead, &heartbeatAt, 1
+	return item.Id
+}
+
+func waitQueueStatus(t *testing.T, repository *fakeRepository, id int64, status constants.QueueStatus) models.TaskQueue {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if item := repository.getQueue(id); item.Status == status {
+			return item
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("task %d on queue was not %s", id, status)
+	return models.TaskQueue{}
+}
+
+func TestWorkerRequeueStaleTask(t *testing.T) {
+	job := NewJob(nil)
+	job.AddTask(newResultTask("a", nil))
+	s, _, repository := newQueueTestWorker(t, job)
+
+	id := enqueueStaleTask(t, repository, s.name, "a")
+	item := waitQueueStatus(t, repository, id, constants.QUEUE_STATUS_SUCCESS)
+	if item.ClaimCount != 2 || *item.NodeId != "worker" || item.Result != `"a"` {
+		t.Fatalf("expected stale task was claimed again by worker, got %+v", item)
+	}
+}
+
+func TestWorkerUnknownTask(t *testing.T) {
+	job := NewJob(nil)
+	job.AddTask(newResultTask("a", nil))
+	s, worker, repository := newQueueTestWorker(t, job)
+
+	cases := []struct {
+		name          string
+		schedulerName string
+		taskId        string
+		exception     string
+	}{
+		{name: "scheduler was not registered", schedulerName: "unknown", taskId: "a", exception: "scheduler unknown was not registered on node worker"},
+		{name: "task was not found", schedulerName: s.name, taskId: "b", exception: "task b was not found in scheduler test on node worker"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id := enqueueStaleTask(t, repository, tc.schedulerName, tc.taskId)
+			/* task was claimed by worker before scheduler was removed from node */
+			ti := time.Now()
+			repository.mutex.Lock()
+			repository.queue[id].NodeId, repository.queue[id].HeartbeatAt = &worker.nodeId, &ti
+			item := *repository.queue[id]
+			repository.mutex.Unlock()
+
+			worker.tasks.Add(1)
+			worker.execute(context.Background(), &item)
+			result := repository.getQueue(id)
+			if result.Status != constants.QUEUE_STATUS_FAILED || !strings.Contains(result.TaskException, tc.exception) {
+				t.Fatalf("expected failed with %q, got %s %q", tc.exception, result.Status, result.TaskException)
+			}
+		})
+	}
+}
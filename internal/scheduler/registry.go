@@ -0,0 +1,278 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
+)
+
+/*
+registry of every scheduler which is able to add, replace and remove on runtime.
+scheduler which was added after registry was started will be started immediately
+*/
+type Registry struct {
+	mutex      *sync.RWMutex
+	schedulers []*SchedulerInstance // sort by order of add scheduler
+	dbAdapter  connection.DatabaseAdapterConnection
+	isStarted  bool
+	elector    *LeaderElector  // cronjob is fired only on leader, nil is every node fire cronjob
+	ctx        context.Context // cancelled on shutdown, job which wait for slot is stopped
+	cancel     context.CancelFunc
+	isShutdown bool
+	running    map[*jobRunner]bool // job of every scheduler which was accepted before shutdown
+	jobs       *sync.WaitGroup
+}
+
+func NewRegistry() *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Registry{
+		mutex:      new(sync.RWMutex),
+		schedulers: make([]*SchedulerInstance, 0),
+		ctx:        ctx,
+		cancel:     cancel,
+		running:    make(map[*jobRunner]bool),
+		jobs:       new(sync.WaitGroup),
+	}
+}
+
+func (r *Registry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.List())
+}
+
+/* scheduler which missed cron tick while node was not leader is caught up when node was elected */
+func (r *Registry) SetElector(elector *LeaderElector) {
+	r.mutex.Lock()
+	r.elector = elector
+	r.mutex.Unlock()
+
+	elector.OnElected(func() {
+		for _, schedulerInstance := range r.List() {
+			schedulerInstance.startCatchUp()
+		}
+	})
+}
+
+func (r *Registry) GetElector() *LeaderElector {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.elector
+}
+
+func (r *Registry) indexOf(name string) int {
+	for index, schedulerInstance := range r.schedulers {
+		if schedulerInstance.GetName() == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func (r *Registry) Get(name string) *SchedulerInstance {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if index := r.indexOf(name); index != -1 {
+		return r.schedulers[index]
+	}
+	return nil
+}
+
+func (r *Registry) List() []*SchedulerInstance {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var schedulers = make([]*SchedulerInstance, len(r.schedulers))
+	copy(schedulers, r.schedulers)
+	return schedulers
+}
+
+func (r *Registry) Add(schedulerInstance *SchedulerInstance) error {
+	r.mutex.Lock()
+	if r.indexOf(schedulerInstance.GetName()) != -1 {
+		r.mutex.Unlock()
+		return fmt.Errorf("scheduler %s was registered", schedulerInstance.GetName())
+	}
+	schedulerInstance.registry = r
+	r.schedulers = append(r.schedulers, schedulerInstance)
+	isStarted, dbAdapter := r.isStarted, r.dbAdapter
+	r.mutex.Unlock()
+
+	if isStarted {
+		return r.startScheduler(schedulerInstance, dbAdapter)
+	}
+	return nil
+}
+
+/*
+replace scheduler which has same name, job which is running on old scheduler will be finished by old definition
+and new job will be run by new definition
+*/
+func (r *Registry) Replace(schedulerInstance *SchedulerInstance) error {
+	r.mutex.Lock()
+	index := r.indexOf(schedulerInstance.GetName())
+	if index == -1 {
+		r.mutex.Unlock()
+		return fmt.Errorf("scheduler %s was not registered", schedulerInstance.GetName())
+	}
+	old := r.schedulers[index]
+	schedulerInstance.registry = r
+	r.schedulers[index] = schedulerInstance
+	isStarted, dbAdapter := r.isStarted, r.dbAdapter
+	r.mutex.Unlock()
+
+	if !isStarted {
+		return nil
+	}
+
+	/* stop cronjob of old scheduler before start new one, for not trigger same schedule twice */
+	old.Scheduler.Clear()
+	schedulerInstance.SetAdapter(dbAdapter)
+	if err := schedulerInstance.Start(); err != nil {
+		r.mutex.Lock()
+		r.schedulers[index] = old
+		r.mutex.Unlock()
+		old.Start()
+		return fmt.Errorf("failed to start scheduler %s: %s", schedulerInstance.GetName(), err.Error())
+	}
+	/* timer of old scheduler will be run by current scheduler in registry, not load again */
+	go old.Stop()
+	return nil
+}
+
+func (r *Registry) Remove(name string) error {
+	r.mutex.Lock()
+	index := r.indexOf(name)
+	if index == -1 {
+		r.mutex.Unlock()
+		return fmt.Errorf("scheduler %s was not registered", name)
+	}
+	old := r.schedulers[index]
+	r.schedulers = append(r.schedulers[:index], r.schedulers[index+1:]...)
+	isStarted := r.isStarted
+	r.mutex.Unlock()
+
+	if isStarted {
+		/* wait running job on background */
+		old.Scheduler.Clear()
+		go old.Stop()
+	}
+	return nil
+}
+
+func (r *Registry) startScheduler(schedulerInstance *SchedulerInstance, dbAdapter connection.DatabaseAdapterConnection) error {
+	schedulerInstance.SetAdapter(dbAdapter)
+	if err := schedulerInstance.Start(); err != nil {
+		return fmt.Errorf("failed to start scheduler %s: %s", schedulerInstance.GetName(), err.Error())
+	}
+	return nil
+}
+
+/* start every scheduler in registry, future job is run by dispatcher */
+func (r *Registry) Start(dbAdapter connection.DatabaseAdapterConnection) error {
+	r.mutex.Lock()
+	r.dbAdapter = dbAdapter
+	r.isStarted = true
+	r.mutex.Unlock()
+
+	for _, schedulerInstance := range r.List() {
+		if err := r.startScheduler(schedulerInstance, dbAdapter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) IsShutdown() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.isShutdown
+}
+
+/* return false when registry was shutdown, job must not be started */
+func (r *Registry) trackJob(runner *jobRunner) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.isShutdown {
+		return false
+	}
+	r.running[runner] = true
+	r.jobs.Add(1)
+	return true
+}
+
+/* id of job which is running on this process */
+func (r *Registry) runningJobIds() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var jobIds = make([]string, 0, len(r.running))
+	for runner := range r.running {
+		jobIds = append(jobIds, runner.id)
+	}
+	return jobIds
+}
+
+func (r *Registry) untrackJob(runner *jobRunner) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.running[runner] {
+		delete(r.running, runner)
+		r.jobs.Done()
+	}
+}
+
+/*
+stop cronjob and reject new trigger, wait for running job until ctx was done.
+job which is still running after that is cancelled and saved as failed,
+trigger which was not started is kept in database for another node
+*/
+func (r *Registry) Shutdown(ctx context.Context) {
+	r.mutex.Lock()
+	r.isStarted = false
+	r.isShutdown = true
+	r.mutex.Unlock()
+
+	schedulers := r.List()
+	for _, schedulerInstance := range schedulers {
+		schedulerInstance.Scheduler.Clear()
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.mutex.RLock()
+		var runners = make([]*jobRunner, 0, len(r.running))
+		for runner := range r.running {
+			runners = append(runners, runner)
+		}
+		r.mutex.RUnlock()
+		for _, runner := range runners {
+			runner.shutdown(errors.New("job was cancelled by shutdown"))
+		}
+		<-done
+	}
+
+	for _, schedulerInstance := range schedulers {
+		schedulerInstance.Stop()
+	}
+}
+
+/* stop every scheduler in registry, wait until running job was finished */
+func (r *Registry) Stop() {
+	r.mutex.Lock()
+	r.isStarted = false
+	r.mutex.Unlock()
+
+	for _, schedulerInstance := range r.List() {
+		schedulerInstance.Stop()
+	}
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+func newTestScheduler(t *testing.T, name string) *SchedulerInstance {
+	job := NewJob(nil)
+	job.AddTask(newNopTask("a"))
+	s := NewScheduler("", name, "", NewDefaultSchedulerConfig())
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRegistryAddReplaceRemove(t *testing.T) {
+	registry := NewRegistry()
+	first := newTestScheduler(t, "a")
+	if err := registry.Add(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(newTestScheduler(t, "a")); err == nil || !strings.Contains(err.Error(), "was registered") {
+		t.Fatalf("expected duplicated scheduler error, got %v", err)
+	}
+	if err := registry.Replace(newTestScheduler(t, "b")); err == nil || !strings.Contains(err.Error(), "was not registered") {
+		t.Fatalf("expected scheduler was not registered error, got %v", err)
+	}
+
+	repository := newFakeRepository()
+	if err := registry.Start(fakeAdapter{repository: repository}); err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Stop()
+
+	/* scheduler which was added after start is started with adapter of registry */
+	second := newTestScheduler(t, "b")
+	if err := registry.Add(second); err != nil {
+		t.Fatal(err)
+	}
+	if second.GetAdapter() == nil || !second.Scheduler.IsRunning() {
+		t.Fatal("scheduler which was added after start was not started")
+	}
+
+	replaced := newTestScheduler(t, "a")
+	if err := registry.Replace(replaced); err != nil {
+		t.Fatal(err)
+	}
+	if registry.Get("a") != replaced || replaced.registry != registry || !replaced.Scheduler.IsRunning() {
+		t.Fatal("scheduler was not replaced")
+	}
+	if names := len(registry.List()); names != 2 {
+		t.Fatalf("expected 2 schedulers, got %d", names)
+	}
+
+	if err := registry.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	if registry.Get("a") != nil || len(registry.List()) != 1 {
+		t.Fatal("scheduler was not removed")
+	}
+	if err := registry.Remove("a"); err == nil {
+		t.Fatal("expected error on remove scheduler which was not registered")
+	}
+}
+
+/* job which was started on old scheduler is finished by old definition, new job is run by new definition */
+func TestRegistryReplaceDuringJob(t *testing.T) {
+	registry := NewRegistry()
+	repository := newFakeRepository()
+	if err := registry.Start(fakeAdapter{repository: repository}); err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Stop()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	job := NewJob(nil)
+	job.AddTask(newResultTaskFunc("old", func() {
+		close(started)
+		<-release
+	}))
+	old := NewScheduler("", "a", "", NewDefaultSchedulerConfig())
+	if err := old.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(old); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		old.Run(newTestTrigger("a", "00000000-0000-0000-0000-000000000001"))
+	}()
+	<-started
+
+	job = NewJob(nil)
+	job.AddTask(newResultTask("new", nil))
+	replaced := NewScheduler("", "a", "", NewDefaultSchedulerConfig())
+	if err := replaced.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Replace(replaced); err != nil {
+		t.Fatal(err)
+	}
+	replaced.Run(newTestTrigger("a", "00000000-0000-0000-0000-000000000002"))
+	close(release)
+	<-done
+
+	for _, taskId := range []string{"old", "new"} {
+		if status := repository.tasks[taskId].Status; status != constants.JOB_STATUS_SUCCESS {
+			t.Errorf("expected task %s %s, got %s", taskId, constants.JOB_STATUS_SUCCESS, status)
+		}
+	}
+}
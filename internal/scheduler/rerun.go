@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/cast"
+)
+
+/* branch task is saved by name of selected pipelines */
+func (jr *jobRunner) serializeTaskValue(value interface{}) (string, error) {
+	if pipelines, ok := value.(task.TaskBranchPipeLines); ok {
+		value = pipelines.GetNames()
+	}
+	return serializeValue(jr.config, value)
+}
+
+func (jr *jobRunner) encodeTaskValue(taskId string, value interface{}) string {
+	data, err := jr.serializeTaskValue(value)
+	if err != nil {
+		log.Errorf("value of task %s on job %s was not saved: %s", taskId, jr.id, err.Error())
+	}
+	return data
+}
+
+func (jr *jobRunner) encodeParameter() string {
+	data, err := serializeValue(jr.config, constants.PARSE_SYNC_MAP_TO_MAP(jr.parameter))
+	if err != nil {
+		log.Errorf("parameter of job %s was not saved: %s", jr.id, err.Error())
+	}
+	return data
+}
+
+func (jr *jobRunner) restoreParameter(data string) {
+	for k, v := range cast.ToStringMap(deserializeValue(jr.config, data)) {
+		jr.parameter.Store(k, v)
+	}
+}
+
+/*
+return task and every task in selected pipelines of branch task which are able to restore,
+branch task is restored when every task in selected pipelines was success
+*/
+func (jr *jobRunner) collectRestorableTask(taskExecution task.Execution, lastest map[string]*models.JobTask) ([]task.Execution, bool) {
+	jobTask, ok := lastest[taskExecution.GetId()]
+	if !ok || jobTask.Status != constants.JOB_STATUS_SUCCESS {
+		return nil, false
+	}
+
+	var tasks = []task.Execution{taskExecution}
+	if branch, ok := taskExecution.(*task.TaskBranch); ok {
+		/* value of previous version is name of one pipeline */
+		pipelines, err := branch.SelectPipelines(deserializeValue(jr.config, jobTask.TaskValue))
+		if err != nil {
+			return nil, false
+		}
+		for _, pipeline := range pipelines {
+			for _, pipelineTask := range pipeline.GetTasks() {
+				restorableTasks, ok := jr.collectRestorableTask(pipelineTask, lastest)
+				if !ok {
+					return nil, false
+				}
+				tasks = append(tasks, restorableTasks...)
+			}
+		}
+	}
+	return tasks, true
+}
+
+/*
+restore state from previous run, attempt of task is continued from previous run.
+on resume mode task which was success will be restored when every upstream was restored
+*/
+func (jr *jobRunner) restore(graph *taskGraph, jobTasks []*models.JobTask, mode constants.RerunMode) {
+	var lastest = make(map[string]*models.JobTask)
+	for _, jobTask := range jobTasks {
+		if previous, ok := lastest[jobTask.TaskId]; !ok || jobTask.Attempt >= previous.Attempt {
+			lastest[jobTask.TaskId] = jobTask
+			jr.attempts[jobTask.TaskId] = jobTask.Attempt
+		}
+	}
+	if mode != constants.RERUN_MODE_RESUME {
+		return
+	}
+
+	var restorable = make(map[*taskNode]bool)
+	var visit func(node *taskNode) bool
+	visit = func(node *taskNode) bool {
+		if ok, visited := restorable[node]; visited {
+			return ok
+		}
+		var isRestorable = true
+		for _, upstream := range node.upstreams {
+			if !visit(upstream) {
+				isRestorable = false
+			}
+		}
+		tasks, ok := jr.collectRestorableTask(node.task, lastest)
+		isRestorable = isRestorable && ok
+		if isRestorable {
+			for _, restorableTask := range tasks {
+				jr.restored[restorableTask.GetId()] = lastest[restorableTask.GetId()]
+			}
+		}
+		restorable[node] = isRestorable
+		return isRestorable
+	}
+	for _, node := range graph.nodes {
+		visit(node)
+	}
+}
+
+/* return value of task which was restored, return false when task must be run */
+func (jr *jobRunner) restoreTask(taskExecution task.Execution) (interface{}, constants.JobStatus, bool) {
+	jobTask, ok := jr.restored[taskExecution.GetId()]
+	if !ok {
+		return nil, "", false
+	}
+
+	value := deserializeValue(jr.config, jobTask.TaskValue)
+	if branch, ok := taskExecution.(*task.TaskBranch); ok {
+		value, _ = branch.SelectPipelines(value)
+	}
+	jr.addTaskResult(taskResult{
+		task:        taskExecution,
+		status:      constants.JOB_STATUS_SUCCESS,
+		attempt:     jobTask.Attempt,
+		startDate:   jobTask.StartDateTime,
+		endDatetime: jobTask.EndDatetime,
+	})
+	jr.taskValue.Store(taskExecution.GetId(), value)
+	return value, constants.JOB_STATUS_SUCCESS, true
+}
+
+func (j *JobInstance) rerun(job *models.Job, jobTasks []*models.JobTask, triggerConfig *sync.Map, mode constants.RerunMode) func() {
+	return func() {
+		ctx := context.Background()
+		runner := newJobRunner(ctx, j, triggerConfig, job.StartDateTime)
+		runner.id = job.JobId
+		runner.rerunOf = job
+		/* trigger was created by first run */
+		runner.triggerType = constants.TRIGGER_TYPE_EXTERNAL
+		runner.restore(j.graph, jobTasks, mode)
+		if mode == constants.RERUN_MODE_RESUME {
+			runner.restoreParameter(job.ParameterString)
+		}
+
+		ctx = context.WithValue(ctx, constants.JOB_RUNNER_INSTANCE_KEY, runner.getRunnerInterface())
+		runner.ctx = ctx
+
+		runner.logjob = &models.Job{
+			SchedulerName:   j.scheduler.name,
+			JobId:           runner.id,
+			Status:          runner.status,
+			StartDateTime:   &runner.executeDatetime,
+			EndDatetime:     nil,
+			ParameterString: job.ParameterString,
+			CreatedAt:       job.CreatedAt,
+			UpdatedAt:       time.Now(),
+		}
+
+		j.process(runner)
+	}
+}
+
+/*
+run job again with same job id, execute datetime of job is kept from previous run.
+resume mode will restore task value and parameter from previous run and start at task which was not success.
+job is claimed by status which was read before run, only one of concurrent rerun of same job is accepted
+*/
+func (s *SchedulerInstance) Rerun(ctx context.Context, job *models.Job, jobTasks []*models.JobTask, triggerConfig *sync.Map, mode constants.RerunMode) error {
+	isClaimed, err := s.dbAdapter.GetRepository().ClaimRerunJob(ctx, job.JobId, constants.ENV_NODE_ID, job.Status)
+	if err != nil {
+		return err
+	}
+	if !isClaimed {
+		return errors.New(constants.ERROR_JOB_CLAIMED)
+	}
+	go s.jobInstance.rerun(job, jobTasks, triggerConfig, mode)()
+	return nil
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func newRerunTestRunner(t *testing.T, job *JobInstance) *jobRunner {
+	s := NewScheduler("", "test", "", NewDefaultSchedulerConfig())
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	return newJobRunner(context.Background(), job, nil, nil)
+}
+
+func newPreviousJobTask(taskId string, status constants.JobStatus, attempt int, value string) *models.JobTask {
+	return &models.JobTask{TaskId: taskId, TaskName: taskId, Status: status, Attempt: attempt, TaskValue: value}
+}
+
+func TestRestore(t *testing.T) {
+	/* a >> b >> c, c was success on older run but b was failed on lastest attempt */
+	previous := []*models.JobTask{
+		newPreviousJobTask("a", constants.JOB_STATUS_SUCCESS, 1, `"a"`),
+		newPreviousJobTask("b", constants.JOB_STATUS_SUCCESS, 1, `"b"`),
+		newPreviousJobTask("b", constants.JOB_STATUS_FAILED, 2, ""),
+		newPreviousJobTask("c", constants.JOB_STATUS_SUCCESS, 1, `"c"`),
+	}
+	newJob := func() *JobInstance {
+		job := NewJob(nil)
+		job.AddTask(newNopTask("a"), newNopTask("b"), newNopTask("c"))
+		return job
+	}
+
+	t.Run("full", func(t *testing.T) {
+		job := newJob()
+		runner := newRerunTestRunner(t, job)
+		runner.restore(job.graph, previous, constants.RERUN_MODE_FULL)
+		if len(runner.restored) != 0 {
+			t.Fatalf("expected nothing restored on full mode, got %v", runner.restored)
+		}
+		expected := map[string]int{"a": 1, "b": 2, "c": 1}
+		for taskId, attempt := range expected {
+			if runner.attempts[taskId] != attempt {
+				t.Fatalf("expected attempt of %s continue from %d, got %d", taskId, attempt, runner.attempts[taskId])
+			}
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		job := newJob()
+		runner := newRerunTestRunner(t, job)
+		runner.restore(job.graph, previous, constants.RERUN_MODE_RESUME)
+		if len(runner.restored) != 1 || runner.restored["a"] == nil {
+			t.Fatalf("expected only a restored, got %v", runner.restored)
+		}
+		value, status, ok := runner.restoreTask(job.tasks[0])
+		if !ok || status != constants.JOB_STATUS_SUCCESS || value != "a" {
+			t.Fatalf("expected value of a restored, got %v %s %v", value, status, ok)
+		}
+		if _, _, ok := runner.restoreTask(job.tasks[2]); ok {
+			t.Fatal("expected c run again because upstream b was not restored")
+		}
+	})
+}
+
+func TestCollectRestorableTask(t *testing.T) {
+	newBranch := func() task.Execution {
+		return task.NewTaskBranch("branch", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+			return []interface{}{"a"}, nil
+		}), task.NewTaskBranchPipeline(map[string][]task.Execution{
+			"a": {newNopTask("a1")},
+			"b": {newNopTask("b1")},
+		}))
+	}
+	cases := []struct {
+		name     string
+		status   constants.JobStatus
+		restored bool
+	}{
+		{name: "selected pipeline was success", status: constants.JOB_STATUS_SUCCESS, restored: true},
+		{name: "selected pipeline was failed", status: constants.JOB_STATUS_FAILED, restored: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			branch := newBranch()
+			job := NewJob(nil)
+			job.AddTask(branch)
+			runner := newRerunTestRunner(t, job)
+			lastest := map[string]*models.JobTask{
+				"branch":      newPreviousJobTask("branch", constants.JOB_STATUS_SUCCESS, 1, `["a"]`),
+				"branch/a/a1": newPreviousJobTask("branch/a/a1", tc.status, 1, `"a1"`),
+			}
+			tasks, ok := runner.collectRestorableTask(branch, lastest)
+			if ok != tc.restored {
+				t.Fatalf("expected restorable %v, got %v", tc.restored, ok)
+			}
+			if !tc.restored {
+				return
+			}
+			var ids = make([]string, 0, len(tasks))
+			for _, restorableTask := range tasks {
+				ids = append(ids, restorableTask.GetId())
+			}
+			if len(ids) != 2 || ids[0] != "branch" || ids[1] != "branch/a/a1" {
+				t.Fatalf("expected branch and task of selected pipeline, got %v", ids)
+			}
+		})
+	}
+}
+
+func TestRestoreParameter(t *testing.T) {
+	job := NewJob(nil)
+	job.AddTask(newNopTask("a"))
+	runner := newRerunTestRunner(t, job)
+	runner.parameter.Store("count", 2)
+	data := runner.encodeParameter()
+
+	restored := newRerunTestRunner(t, job)
+	restored.restoreParameter(data)
+	value, ok := restored.parameter.Load("count")
+	if !ok || value != float64(2) {
+		t.Fatalf("expected parameter restored as json number, got %v", value)
+	}
+}
+
+/* wait until rerun of job was finished */
+func waitJobStatus(t *testing.T, repository *fakeRepository, jobId string, status constants.JobStatus) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := repository.GetOneJob(context.Background(), jobId); job != nil && job.Status == status {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s was not %s", jobId, status)
+}
+
+func TestRerunClaim(t *testing.T) {
+	const jobId = "00000000-0000-0000-0000-000000000001"
+	var calls int32
+	job := NewJob(nil)
+	job.AddTask(newResultTaskFunc("a", func() { atomic.AddInt32(&calls, 1) }))
+	s := NewScheduler("", "test", "", NewDefaultSchedulerConfig())
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	s.SetAdapter(fakeAdapter{repository: repository})
+	previous := &models.Job{SchedulerName: s.name, JobId: jobId, Status: constants.JOB_STATUS_FAILED}
+	repository.UpsertJob(context.Background(), previous)
+
+	/* concurrent rerun read same status, only one claim is accepted */
+	var wg sync.WaitGroup
+	var accepted, rejected int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Rerun(context.Background(), previous, nil, nil, constants.RERUN_MODE_FULL)
+			switch {
+			case err == nil:
+				atomic.AddInt32(&accepted, 1)
+			case err.Error() == constants.ERROR_JOB_CLAIMED:
+				atomic.AddInt32(&rejected, 1)
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted != 1 || rejected != 4 {
+		t.Fatalf("expected 1 accepted and 4 rejected rerun, got %d and %d", accepted, rejected)
+	}
+	waitJobStatus(t, repository, jobId, constants.JOB_STATUS_SUCCESS)
+	if calls != 1 {
+		t.Fatalf("expected job run once, got %d", calls)
+	}
+
+	/* status was changed after it was read */
+	if err := s.Rerun(context.Background(), previous, nil, nil, constants.RERUN_MODE_FULL); err == nil || err.Error() != constants.ERROR_JOB_CLAIMED {
+		t.Fatalf("expected stale status rejected, got %v", err)
+	}
+}
+
+func TestRerunHandBack(t *testing.T) {
+	const jobId = "00000000-0000-0000-0000-000000000001"
+	registry := NewRegistry()
+	s := newTestScheduler(t, "test")
+	if err := registry.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	if err := registry.Start(fakeAdapter{repository: repository}); err != nil {
+		t.Fatal(err)
+	}
+	previous := &models.Job{SchedulerName: s.name, JobId: jobId, Status: constants.JOB_STATUS_FAILED, Exception: "boom"}
+	repository.UpsertJob(context.Background(), previous)
+	registry.Shutdown(context.Background())
+
+	/* rerun which was claimed but not started is saved back as previous run */
+	if err := s.Rerun(context.Background(), previous, nil, nil, constants.RERUN_MODE_FULL); err != nil {
+		t.Fatal(err)
+	}
+	waitJobStatus(t, repository, jobId, constants.JOB_STATUS_FAILED)
+	if job, _ := repository.GetOneJob(context.Background(), jobId); job.Exception != "boom" {
+		t.Fatalf("expected previous run saved back, got %+v", job)
+	}
+}
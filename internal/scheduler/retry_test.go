@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+/* task which is failed until it was called more than failures */
+func newFlakyTask(name string, failures int32, calls *int32) task.Execution {
+	return task.NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			return nil, errors.New("boom")
+		}
+		return name, nil
+	}))
+}
+
+func TestRetry(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryTimes int
+		failures   int32
+		status     constants.JobStatus
+		attempts   []constants.JobStatus
+	}{
+		{name: "no retry", retryTimes: 0, failures: 1, status: constants.JOB_STATUS_FAILED, attempts: []constants.JobStatus{constants.JOB_STATUS_FAILED}},
+		{name: "success after failure", retryTimes: 2, failures: 2, status: constants.JOB_STATUS_SUCCESS, attempts: []constants.JobStatus{constants.JOB_STATUS_UP_FOR_RETRY, constants.JOB_STATUS_UP_FOR_RETRY, constants.JOB_STATUS_SUCCESS}},
+		{name: "retry exhausted", retryTimes: 1, failures: 5, status: constants.JOB_STATUS_FAILED, attempts: []constants.JobStatus{constants.JOB_STATUS_UP_FOR_RETRY, constants.JOB_STATUS_FAILED}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			job := NewJob(nil)
+			job.AddTask(newFlakyTask("a", tc.failures, &calls))
+			config := NewDefaultSchedulerConfig()
+			config.RetryTimes = tc.retryTimes
+			config.RetryDelay = time.Millisecond
+
+			repository := runTestJobWithConfig(t, job, config)
+			if repository.job.Status != tc.status {
+				t.Fatalf("expected job %s, got %s", tc.status, repository.job.Status)
+			}
+			if int(calls) != len(tc.attempts) {
+				t.Fatalf("expected %d calls, got %d", len(tc.attempts), calls)
+			}
+			/* last status of each attempt */
+			var attempts = make([]constants.JobStatus, len(tc.attempts))
+			for _, jobTask := range repository.history {
+				if jobTask.Attempt < 1 || jobTask.Attempt > len(attempts) {
+					t.Fatalf("unexpected attempt %d", jobTask.Attempt)
+				}
+				attempts[jobTask.Attempt-1] = jobTask.Status
+			}
+			for index, status := range tc.attempts {
+				if attempts[index] != status {
+					t.Errorf("expected attempt %d %s, got %s", index+1, status, attempts[index])
+				}
+			}
+		})
+	}
+}
+
+func TestRetryDelayCancelledByJob(t *testing.T) {
+	var calls int32
+	job := NewJob(nil)
+	job.AddTask(newFlakyTask("a", 5, &calls))
+	config := NewDefaultSchedulerConfig()
+	config.RetryTimes = 3
+	config.RetryDelay = time.Hour
+	config.JobTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	repository := runTestJobWithConfig(t, job, config)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("retry delay was not cancelled by job, took %s", elapsed)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+	if status := repository.tasks["a"].Status; status != constants.JOB_STATUS_TIMEOUT {
+		t.Fatalf("expected task %s, got %s", constants.JOB_STATUS_TIMEOUT, status)
+	}
+}
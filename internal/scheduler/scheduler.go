@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/logger"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
 	"github.com/go-co-op/gocron"
 	"github.com/labstack/gommon/log"
 )
@@ -23,11 +26,18 @@ type SchedulerInstance struct {
 	config         SchedulerConfig
 	logger         *logger.Log
 	dbAdapter      connection.DatabaseAdapterConnection
+	registry       *Registry
+	slots          chan struct{} // slot of MaxActiveConcurrent which is shared by every job of scheduler
+	stateMutex     *sync.RWMutex
+	state          models.SchedulerState
 }
 
 func NewScheduler(cronExpression string, name string, description string, config SchedulerConfig) *SchedulerInstance {
 	scheduler := gocron.NewScheduler(time.Local)
-	scheduler.SetMaxConcurrentJobs(config.MaxActiveConcurrent, gocron.WaitMode)
+	var slots chan struct{}
+	if config.MaxActiveConcurrent > 0 {
+		slots = make(chan struct{}, config.MaxActiveConcurrent)
+	}
 
 	return &SchedulerInstance{
 		Scheduler:      scheduler,
@@ -36,6 +46,9 @@ func NewScheduler(cronExpression string, name string, description string, config
 		cronExpression: cronExpression,
 		config:         config,
 		logger:         logger.NewLoggerWithFile(constants.LOG_PATH_SCHEDULER),
+		stateMutex:     new(sync.RWMutex),
+		state:          models.SchedulerState{SchedulerName: name},
+		slots:          slots,
 	}
 }
 
@@ -44,6 +57,8 @@ func (s *SchedulerInstance) MarshalJSON() ([]byte, error) {
 		Name        string                   `json:"name"`
 		Cronjob     string                   `json:"cronjob_expression"`
 		IsRunning   bool                     `json:"is_running"`
+		IsPaused    bool                     `json:"is_paused"`
+		IsReject    bool                     `json:"reject_trigger"`
 		Description string                   `json:"description"`
 		Arguments   map[string]interface{}   `json:"arguments"`
 		LastRun     string                   `json:"last_run"`
@@ -51,14 +66,20 @@ func (s *SchedulerInstance) MarshalJSON() ([]byte, error) {
 		PreviousRun string                   `json:"previous_run"`
 		Config      SchedulerConfig          `json:"config"`
 		Tasks       []map[string]interface{} `json:"tasks"`
+		Edges       []taskEdge               `json:"edges"`
+		Groups      []*task.TaskGroup        `json:"groups"`
 	}
 	var sh = ptr{
 		Name:        s.name,
 		Cronjob:     s.cronExpression,
 		IsRunning:   s.Scheduler.IsRunning(),
+		IsPaused:    s.GetState().IsPaused,
+		IsReject:    s.GetState().RejectTrigger,
 		Config:      s.config,
 		Description: s.description,
 		Tasks:       make([]map[string]interface{}, 0),
+		Edges:       make([]taskEdge, 0),
+		Groups:      make([]*task.TaskGroup, 0),
 	}
 	if s.jobInstance != nil {
 		sh.Arguments = s.jobInstance.arguments
@@ -76,6 +97,10 @@ func (s *SchedulerInstance) MarshalJSON() ([]byte, error) {
 				sh.Tasks = append(sh.Tasks, m)
 			}
 		}
+		if s.jobInstance.graph != nil {
+			sh.Edges = s.jobInstance.graph.getEdges()
+		}
+		sh.Groups = append(sh.Groups, s.jobInstance.groups...)
 	}
 	return json.Marshal(sh)
 }
@@ -96,13 +121,85 @@ func (s SchedulerInstance) GetAdapter() connection.DatabaseAdapterConnection {
 	return s.dbAdapter
 }
 
+func (s *SchedulerInstance) GetState() models.SchedulerState {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+	return s.state
+}
+
+func (s *SchedulerInstance) IsPaused() bool {
+	return s.GetState().IsPaused
+}
+
+func (s *SchedulerInstance) setState(state models.SchedulerState) {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	s.state = state
+}
+
+/* state is shared by every replica, load it before use for get lastest state */
+func (s *SchedulerInstance) LoadState(ctx context.Context) error {
+	state, err := s.dbAdapter.GetRepository().GetSchedulerState(ctx, s.name)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &models.SchedulerState{SchedulerName: s.name}
+	}
+	s.setState(*state)
+	return nil
+}
+
+func (s *SchedulerInstance) saveState(ctx context.Context, isPaused bool, rejectTrigger bool) error {
+	state := s.GetState()
+	state.IsPaused = isPaused
+	state.RejectTrigger = rejectTrigger
+	state.UpdatedAt = time.Now()
+	if state.CreatedAt.IsZero() {
+		state.CreatedAt = state.UpdatedAt
+	}
+	if err := s.dbAdapter.GetRepository().UpsertSchedulerState(ctx, &state); err != nil {
+		return err
+	}
+	s.setState(state)
+	return nil
+}
+
+/* paused scheduler will not run by cronjob, manual trigger is rejected when rejectTrigger is true */
+func (s *SchedulerInstance) Pause(ctx context.Context, rejectTrigger bool) error {
+	if err := s.saveState(ctx, true, rejectTrigger); err != nil {
+		return err
+	}
+	s.logger.Info("pause scheduler", map[string]interface{}{
+		"scheduler_name": s.name,
+		"reject_trigger": rejectTrigger,
+	})
+	return nil
+}
+
+func (s *SchedulerInstance) Resume(ctx context.Context) error {
+	if err := s.saveState(ctx, false, false); err != nil {
+		return err
+	}
+	s.logger.Info("resume scheduler", map[string]interface{}{
+		"scheduler_name": s.name,
+	})
+	return nil
+}
+
 func (s *SchedulerInstance) Start() error {
+	if err := s.LoadState(context.Background()); err != nil {
+		return err
+	}
 	if s.cronExpression != "" {
 		_, fn := s.jobInstance.trigger("", nil, nil)
 		job, err := s.Scheduler.Cron(s.cronExpression).Do(fn)
 		if err != nil {
 			return err
 		}
+		if s.config.JobMode == constants.JOB_MODE_SIGNLETON {
+			job.SingletonMode()
+		}
 		s.jobInstance.Job = job
 	}
 
@@ -111,6 +208,7 @@ func (s *SchedulerInstance) Start() error {
 		"scheduler_cronjob_expression": s.cronExpression,
 		"scheduler_name":               s.name,
 	})
+	s.startCatchUp()
 	return nil
 }
 
@@ -125,44 +223,101 @@ func (s *SchedulerInstance) RegisterJob(jobInstance *JobInstance) error {
 	if jobInstance.GetTotalTask() == 0 {
 		return errors.New("required any task in jobInstance")
 	}
+	if err := task.AssignIds(jobInstance.tasks); err != nil {
+		return fmt.Errorf("scheduler %s: %s", s.name, err.Error())
+	}
+	if err := jobInstance.buildGraph(); err != nil {
+		return fmt.Errorf("scheduler %s: %s", s.name, err.Error())
+	}
 	jobInstance.SetScheduler(s)
 	s.jobInstance = jobInstance
 
 	return nil
 }
 
+/* cronjob of scheduler is fired by leader, validate fencing token on database for not trigger by replaced leader */
+func (s *SchedulerInstance) isLeader(ctx context.Context) (bool, error) {
+	if s.registry == nil || s.registry.GetElector() == nil {
+		return true, nil
+	}
+	return s.registry.GetElector().Validate(ctx)
+}
+
+/* lease for fence trigger of cronjob, lease is nil without leader election */
+func (s *SchedulerInstance) fencingLease() (*models.LeaderLease, bool) {
+	if s.registry == nil || s.registry.GetElector() == nil {
+		return nil, true
+	}
+	lease := s.registry.GetElector().GetFencingLease()
+	return lease, lease != nil
+}
+
+/* context which is cancelled when registry was shutdown */
+func (s *SchedulerInstance) shutdownCtx() context.Context {
+	if s.registry == nil {
+		return context.Background()
+	}
+	return s.registry.ctx
+}
+
+/* job which is not in registry is always accepted */
+func (s *SchedulerInstance) trackJob(runner *jobRunner) bool {
+	if s.registry == nil {
+		return true
+	}
+	return s.registry.trackJob(runner)
+}
+
+func (s *SchedulerInstance) untrackJob(runner *jobRunner) {
+	if s.registry != nil {
+		s.registry.untrackJob(runner)
+	}
+}
+
+/*
+trigger of job which was not started is handed back to database for another node,
+rerun has not own trigger, job which was claimed for rerun is saved back as previous run
+*/
+func (s *SchedulerInstance) handBack(runner *jobRunner) {
+	if runner.rerunOf != nil {
+		if err := s.dbAdapter.GetRepository().UpsertJob(context.Background(), runner.rerunOf); err != nil {
+			log.Errorf("failed to hand back job %s of rerun with error: %s", runner.id, err.Error())
+		}
+		return
+	}
+	if err := s.dbAdapter.GetRepository().ReleaseTrigger(context.Background(), runner.id); err != nil {
+		log.Errorf("failed to hand back trigger of job %s with error: %s", runner.id, err.Error())
+	}
+}
+
+/* trigger must be saved before run, future trigger is run by dispatcher when execute datetime was due */
 func (s *SchedulerInstance) Run(trigger *models.Trigger) string {
 	/* ตั้งเวลาล่วงหน้า */
 	if trigger.ExecuteDatetime != (time.Time{}) && trigger.ExecuteDatetime.Sub(time.Now()) > 0 {
-		duration := trigger.ExecuteDatetime.Sub(time.Now())
-		jobId, fn := s.jobInstance.trigger(trigger.JobId, trigger.GetConfigMutex(), &trigger.ExecuteDatetime)
-
-		trigger.JobId = jobId
-		go func(trigger models.Trigger, duration time.Duration, call func()) {
-			time.Sleep(duration)
-			trigger.IsTrigger = true
-			checkTrigger, err := s.dbAdapter.GetRepository().ExecuteFutureJob(context.Background(), &trigger)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			if checkTrigger != nil && checkTrigger.JobId != "" && checkTrigger.IsActive {
-				fn()
-			}
-		}(*trigger, duration, fn)
-		return jobId
+		return trigger.JobId
 	}
 	/* run ทันที */
-	jobId, fn := s.jobInstance.trigger(trigger.JobId, trigger.GetConfigMutex(), &trigger.ExecuteDatetime)
-	trigger.JobId = jobId
+	if err := s.execute(trigger); err != nil {
+		return ""
+	}
+	return trigger.JobId
+}
+
+func (s *SchedulerInstance) execute(trigger *models.Trigger) error {
 	trigger.IsTrigger = true
 	checkTrigger, err := s.dbAdapter.GetRepository().ExecuteFutureJob(context.Background(), trigger)
 	if err != nil {
 		log.Error(err)
-		return ""
+		return err
 	}
 	if checkTrigger != nil && checkTrigger.JobId != "" && checkTrigger.IsActive {
-		fn()
+		s.dispatch(trigger)
 	}
-	return jobId
+	return nil
+}
+
+/* run job of trigger which was claimed on database */
+func (s *SchedulerInstance) dispatch(trigger *models.Trigger) {
+	_, fn := s.jobInstance.trigger(trigger.JobId, trigger.GetConfigMutex(), &trigger.ExecuteDatetime)
+	fn()
 }
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+type pokeResult struct {
+	value interface{}
+	ok    bool
+}
+
+/*
+poke sensor until condition was met, each poke is called with deadline of task.
+reschedule mode release slot of scheduler between poke and record each wait as attempt of task
+*/
+func (jr *jobRunner) callSensor(sensor *task.TaskSensor, taskResult *taskResult) (interface{}, error) {
+	var deadline time.Time
+	if sensor.GetTimeout() > 0 {
+		deadline = time.Now().Add(sensor.GetTimeout())
+	}
+
+	for {
+		res, err := jr.call(sensor, func(ctx context.Context) (interface{}, error) {
+			value, ok, err := sensor.Poke(ctx)
+			return pokeResult{value: value, ok: ok}, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result := res.(pokeResult); result.ok {
+			return result.value, nil
+		}
+
+		wait := sensor.GetPokeInterval()
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return nil, sensor.NewTimeoutError()
+			}
+			if remaining < wait {
+				wait = remaining
+			}
+		}
+
+		isReschedule := sensor.GetMode() == constants.SENSOR_MODE_RESCHEDULE
+		if isReschedule {
+			ti := time.Now()
+			taskResult.status = constants.JOB_STATUS_UP_FOR_RESCHEDULE
+			taskResult.endDatetime = &ti
+			jr.saveJobTask(sensor, *taskResult, nil, nil)
+			jr.slot.release()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-jr.jobCtx.Done():
+		}
+
+		if isReschedule {
+			/* job which was cancelled during waiting for slot does not hold slot again, slot of task must not be released after */
+			if err := jr.slot.acquire(jr.jobCtx); err != nil {
+				jr.unheldSlots.Store(sensor.GetId(), true)
+				return nil, err
+			}
+			taskResult.status = constants.JOB_STATUS_RUNNING
+			taskResult.attempt++
+			taskResult.startDate = time.Now()
+			taskResult.endDatetime = nil
+			jr.saveJobTask(sensor, *taskResult, nil, nil)
+		}
+		if err := jr.jobCtx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
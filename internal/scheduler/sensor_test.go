@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+type fakeSensor struct {
+	once  sync.Once
+	poked chan struct{}
+}
+
+func (s *fakeSensor) GetName() string { return "fake" }
+
+/* condition is never met */
+func (s *fakeSensor) Poke(ctx context.Context) (interface{}, bool, error) {
+	s.once.Do(func() { close(s.poked) })
+	return nil, false, nil
+}
+
+func TestRescheduleSensorCancelledWaitingSlot(t *testing.T) {
+	config := NewDefaultSchedulerConfig()
+	config.MaxActiveConcurrent = 1
+	fake := &fakeSensor{poked: make(chan struct{})}
+	sensor := task.NewTaskSensor("sensor", fake, task.SensorConfig{Mode: constants.SENSOR_MODE_RESCHEDULE, PokeInterval: 10 * time.Millisecond})
+	sibling := newNopTask("sibling")
+	job := NewJob(nil)
+	job.AddTask(sensor, sibling)
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	s.SetAdapter(fakeAdapter{repository: newFakeRepository()})
+
+	runner := newJobRunner(context.Background(), job, nil, nil)
+	jobCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runner.jobCtx = jobCtx
+	if err := runner.slot.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	/* another job take slot which was released by sensor, then job is cancelled during waiting for slot */
+	go func() {
+		<-fake.poked
+		s.slots <- struct{}{}
+		cancel()
+	}()
+	result := taskResult{task: sensor, status: constants.JOB_STATUS_RUNNING, attempt: 1, startDate: time.Now()}
+	if _, err := runner.callSensor(sensor.(*task.TaskSensor), &result); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled error, got %v", err)
+	}
+
+	/* slot of sibling which was held after must not be released by sensor */
+	<-s.slots
+	if err := runner.slot.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	runner.releaseTaskSlot(sensor)
+	if runner.slot.count != 1 || len(s.slots) != 1 {
+		t.Fatalf("expected slot of sibling was held, got count %d and %d slot in use", runner.slot.count, len(s.slots))
+	}
+	runner.releaseTaskSlot(sibling)
+	if runner.slot.count != 0 || len(s.slots) != 0 {
+		t.Fatalf("expected slot was released, got count %d and %d slot in use", runner.slot.count, len(s.slots))
+	}
+}
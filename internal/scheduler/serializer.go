@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/* serializer of task value and parameter which are saved in database */
+type Serializer interface {
+	GetName() string
+	Serialize(value interface{}) (string, error)
+	Deserialize(data string) (interface{}, error)
+}
+
+type jsonSerializer struct{}
+
+/* value is restored as type of json, struct will be restored as map[string]interface{} */
+func NewJsonSerializer() Serializer {
+	return jsonSerializer{}
+}
+
+func (s jsonSerializer) GetName() string {
+	return "json"
+}
+
+func (s jsonSerializer) Serialize(value interface{}) (string, error) {
+	bu, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(bu), nil
+}
+
+func (s jsonSerializer) Deserialize(data string) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+/* return error when value can not be serialized or is larger than max value size of config, caller saves empty string instead */
+func serializeValue(config SchedulerConfig, value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	data, err := config.getSerializer().Serialize(value)
+	if err != nil {
+		return "", err
+	}
+	if config.MaxValueSize > 0 && len(data) > config.MaxValueSize {
+		return "", fmt.Errorf("size of value is %d bytes over limit %d bytes", len(data), config.MaxValueSize)
+	}
+	return data, nil
+}
+
+func deserializeValue(config SchedulerConfig, data string) interface{} {
+	if data == "" {
+		return nil
+	}
+	value, err := config.getSerializer().Deserialize(data)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+/* deserialize value which was saved by scheduler, value of scheduler which was removed is deserialized as json */
+func DeserializeValue(schedulerInstance *SchedulerInstance, data string) interface{} {
+	if schedulerInstance == nil {
+		return deserializeValue(NewDefaultSchedulerConfig(), data)
+	}
+	return deserializeValue(schedulerInstance.config, data)
+}
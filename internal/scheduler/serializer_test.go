@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+func TestSerializeRoundTrip(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	config := NewDefaultSchedulerConfig()
+	cases := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{name: "nil", value: nil, expected: nil},
+		{name: "string", value: "a", expected: "a"},
+		{name: "number", value: 2, expected: float64(2)},
+		{name: "list", value: []string{"a", "b"}, expected: []interface{}{"a", "b"}},
+		{name: "struct", value: payload{Name: "a", Count: 1}, expected: map[string]interface{}{"name": "a", "count": float64(1)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := serializeValue(config, tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value := deserializeValue(config, data); !reflect.DeepEqual(value, tc.expected) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, value)
+			}
+		})
+	}
+}
+
+func TestSerializeValueSize(t *testing.T) {
+	config := NewDefaultSchedulerConfig()
+	config.MaxValueSize = 8
+	if _, err := serializeValue(config, "abc"); err != nil {
+		t.Fatalf("expected value under limit saved, got %v", err)
+	}
+	if _, err := serializeValue(config, strings.Repeat("a", 16)); err == nil || !strings.Contains(err.Error(), "over limit") {
+		t.Fatalf("expected over limit error, got %v", err)
+	}
+
+	config.MaxValueSize = 0
+	if _, err := serializeValue(config, strings.Repeat("a", 1024)); err != nil {
+		t.Fatalf("expected no limit, got %v", err)
+	}
+	if _, err := serializeValue(config, make(chan int)); err == nil {
+		t.Fatal("expected error of value which can not be serialized")
+	}
+}
+
+/* task which value is over limit is still success, value is saved as empty string */
+func TestOversizedTaskValue(t *testing.T) {
+	config := NewDefaultSchedulerConfig()
+	config.MaxValueSize = 4
+	job := NewJob(nil)
+	job.AddTask(newResultTask("oversized", nil), newResultTask("a", nil))
+	repository := runTestJobWithConfig(t, job, config)
+	if repository.job.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected job success, got %s", repository.job.Status)
+	}
+	if jobTask := repository.tasks["oversized"]; jobTask.Status != constants.JOB_STATUS_SUCCESS || jobTask.TaskValue != "" {
+		t.Fatalf("expected oversized value not saved, got %s %q", jobTask.Status, jobTask.TaskValue)
+	}
+	if jobTask := repository.tasks["a"]; jobTask.TaskValue != `"a"` {
+		t.Fatalf("expected value under limit saved, got %q", jobTask.TaskValue)
+	}
+}
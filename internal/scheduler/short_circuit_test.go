@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func TestShortCircuit(t *testing.T) {
+	cases := []struct {
+		name      string
+		condition interface{}
+		job       constants.JobStatus
+		statuses  map[string]constants.JobStatus
+	}{
+		{
+			name: "continue", condition: true, job: constants.JOB_STATUS_SUCCESS,
+			statuses: map[string]constants.JobStatus{"check": constants.JOB_STATUS_SUCCESS, "a": constants.JOB_STATUS_SUCCESS, "b": constants.JOB_STATUS_SUCCESS, "other": constants.JOB_STATUS_SUCCESS},
+		},
+		{
+			name: "short circuited", condition: false, job: constants.JOB_STATUS_SUCCESS,
+			statuses: map[string]constants.JobStatus{"check": constants.JOB_STATUS_SUCCESS, "a": constants.JOB_STATUS_SKIPPED, "b": constants.JOB_STATUS_SKIPPED, "other": constants.JOB_STATUS_SUCCESS},
+		},
+		{
+			name: "not bool", condition: "yes", job: constants.JOB_STATUS_FAILED,
+			statuses: map[string]constants.JobStatus{"check": constants.JOB_STATUS_FAILED, "a": constants.JOB_STATUS_SKIPPED},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := task.NewTaskShortCircuit("check", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				return tc.condition, nil
+			}))
+			/* every downstream is skipped include task with trigger rule all done */
+			a, b, other := newResultTask("a", nil), newResultTask("b", nil), newResultTask("other", nil)
+			b.SetTriggerRule(constants.TRIGGER_RULE_ALL_DONE)
+			job := NewJob(nil)
+			job.AddTask(check, a, b, other)
+			job.SetDownstream(check, a)
+			job.SetDownstream(a, b)
+
+			repository := runTestJob(t, job)
+			if repository.job.Status != tc.job {
+				t.Errorf("expected job %s, got %s", tc.job, repository.job.Status)
+			}
+			for taskId, status := range tc.statuses {
+				if jobTask := repository.tasks[taskId]; jobTask.Status != status {
+					t.Errorf("expected task %s %s, got %s", taskId, status, jobTask.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func (f *fakeRepository) ReleaseTrigger(ctx context.Context, jobId string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.released = append(f.released, jobId)
+	return nil
+}
+
+func (f *fakeRepository) getReleased() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append(make([]string, 0, len(f.released)), f.released...)
+}
+
+/* registry with started scheduler which has one task, task is started after started was closed */
+func newShutdownTestRegistry(t *testing.T, execution task.Execution, maxActiveConcurrent int) (*Registry, *SchedulerInstance, *fakeRepository) {
+	job := NewJob(nil)
+	job.AddTask(execution)
+	config := NewDefaultSchedulerConfig()
+	config.MaxActiveConcurrent = maxActiveConcurrent
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	if err := registry.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	repository := newFakeRepository()
+	if err := registry.Start(fakeAdapter{repository: repository}); err != nil {
+		t.Fatal(err)
+	}
+	return registry, s, repository
+}
+
+func TestShutdownDrain(t *testing.T) {
+	started := make(chan struct{})
+	registry, s, repository := newShutdownTestRegistry(t, task.NewTask("a", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return "a", ctx.Err()
+	})), 1)
+	go s.Run(newTestTrigger(s.name, "00000000-0000-0000-0000-000000000001"))
+	<-started
+
+	/* running job is finished within grace period */
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	registry.Shutdown(ctx)
+	if ctx.Err() != nil {
+		t.Fatal("expected shutdown returned before grace period")
+	}
+	if job, _ := repository.GetOneJob(context.Background(), "00000000-0000-0000-0000-000000000001"); job == nil || job.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected job was drained with success, got %+v", job)
+	}
+
+	/* trigger after shutdown is handed back without run */
+	s.Run(newTestTrigger(s.name, "00000000-0000-0000-0000-000000000002"))
+	if job, _ := repository.GetOneJob(context.Background(), "00000000-0000-0000-0000-000000000002"); job != nil {
+		t.Fatalf("expected job was not run after shutdown, got %s", job.Status)
+	}
+	if released := repository.getReleased(); len(released) != 1 || released[0] != "00000000-0000-0000-0000-000000000002" {
+		t.Fatalf("expected trigger was handed back, got %v", released)
+	}
+}
+
+func TestShutdownCancel(t *testing.T) {
+	started := make(chan struct{})
+	registry, s, repository := newShutdownTestRegistry(t, task.NewTask("a", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})), 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(newTestTrigger(s.name, "00000000-0000-0000-0000-000000000001"))
+	}()
+	<-started
+	/* job which wait for slot is handed back when shutdown was started */
+	waiting := make(chan struct{})
+	go func() {
+		defer close(waiting)
+		s.Run(newTestTrigger(s.name, "00000000-0000-0000-0000-000000000002"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	registry.Shutdown(ctx)
+	<-done
+	<-waiting
+
+	job, _ := repository.GetOneJob(context.Background(), "00000000-0000-0000-0000-000000000001")
+	if job == nil || job.Status != constants.JOB_STATUS_FAILED || job.Exception != "job was cancelled by shutdown" {
+		t.Fatalf("expected job was cancelled after grace period, got %+v", job)
+	}
+	if job, _ := repository.GetOneJob(context.Background(), "00000000-0000-0000-0000-000000000002"); job != nil {
+		t.Fatalf("expected waiting job was not run, got %s", job.Status)
+	}
+	if released := repository.getReleased(); len(released) != 1 || released[0] != "00000000-0000-0000-0000-000000000002" {
+		t.Fatalf("expected trigger of waiting job was handed back, got %v", released)
+	}
+}
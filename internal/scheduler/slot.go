@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+slot of MaxActiveConcurrent, job hold one slot of scheduler while any task of job is running.
+sensor on reschedule mode release slot of job during waiting for next poke
+*/
+type jobSlot struct {
+	mutex *sync.Mutex
+	count int
+	slots chan struct{} // nil is no limit
+}
+
+func newJobSlot(slots chan struct{}) *jobSlot {
+	return &jobSlot{
+		mutex: new(sync.Mutex),
+		slots: slots,
+	}
+}
+
+/* wait for slot of scheduler when job did not hold slot */
+func (s *jobSlot) acquire(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.count == 0 && s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.count++
+	return nil
+}
+
+/* release slot of scheduler when nothing in job hold slot */
+func (s *jobSlot) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.count == 0 {
+		return
+	}
+	s.count--
+	if s.count == 0 && s.slots != nil {
+		<-s.slots
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobSlot(t *testing.T) {
+	slots := make(chan struct{}, 1)
+	first, second := newJobSlot(slots), newJobSlot(slots)
+
+	/* nested acquire of same job hold only one slot */
+	if err := first.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(slots) != 1 {
+		t.Fatalf("expected 1 slot in use, got %d", len(slots))
+	}
+
+	/* another job wait until every holder of first job was released */
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := second.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	first.release()
+	if len(slots) != 1 {
+		t.Fatalf("expected slot was held until last release, got %d", len(slots))
+	}
+	first.release()
+	if len(slots) != 0 {
+		t.Fatalf("expected slot was released, got %d", len(slots))
+	}
+	first.release()
+	if len(slots) != 0 {
+		t.Fatalf("expected release without acquire is ignored, got %d", len(slots))
+	}
+
+	if err := second.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	second.release()
+}
+
+func TestJobSlotWithoutLimit(t *testing.T) {
+	slot := newJobSlot(nil)
+	for i := 0; i < 3; i++ {
+		if err := slot.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		slot.release()
+	}
+	if slot.count != 0 {
+		t.Fatalf("expected count 0, got %d", slot.count)
+	}
+}
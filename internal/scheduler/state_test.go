@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+func TestPauseResume(t *testing.T) {
+	repository := newFakeRepository()
+	s := newTestScheduler(t, "a")
+	s.SetAdapter(fakeAdapter{repository: repository})
+	if err := s.LoadState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsPaused() {
+		t.Fatal("scheduler without state must not be paused")
+	}
+
+	if err := s.Pause(context.Background(), true); err != nil {
+		t.Fatal(err)
+	}
+	/* state is shared by every replica */
+	replica := newTestScheduler(t, "a")
+	replica.SetAdapter(fakeAdapter{repository: repository})
+	if err := replica.LoadState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if state := replica.GetState(); !state.IsPaused || !state.RejectTrigger || state.CreatedAt.IsZero() {
+		t.Fatalf("expected paused state which reject trigger, got %+v", state)
+	}
+
+	if err := replica.Resume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if state := s.GetState(); state.IsPaused || state.RejectTrigger {
+		t.Fatalf("expected resumed state, got %+v", state)
+	}
+}
+
+/* cronjob is skipped by paused state which was saved by another replica, manual trigger is run when trigger was not rejected */
+func TestPausedCronjob(t *testing.T) {
+	repository := newFakeRepository()
+	job := NewJob(nil)
+	job.AddTask(newResultTask("a", nil))
+	s := NewScheduler("* * * * *", "a", "", NewDefaultSchedulerConfig())
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	s.SetAdapter(fakeAdapter{repository: repository})
+
+	replica := newTestScheduler(t, "a")
+	replica.SetAdapter(fakeAdapter{repository: repository})
+	if err := replica.Pause(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	_, fn := job.trigger("", nil, nil)
+	fn()
+	if len(repository.triggers) != 0 || repository.job.JobId != "" {
+		t.Fatal("cronjob of paused scheduler was run")
+	}
+
+	s.Run(newTestTrigger("a", "00000000-0000-0000-0000-000000000001"))
+	if repository.job.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected manual trigger was run, got %s", repository.job.Status)
+	}
+
+	if err := replica.Resume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, fn = job.trigger("", nil, nil)
+	fn()
+	if len(repository.triggers) != 1 || repository.triggers[0].TriggerType != constants.TRIGGER_TYPE_SCHEDULE {
+		t.Fatalf("expected schedule trigger of resumed scheduler, got %v", repository.triggers)
+	}
+}
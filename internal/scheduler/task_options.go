@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+	"github.com/labstack/gommon/log"
+)
+
+/* option of task override config of scheduler */
+func (jr *jobRunner) getRetryTimes(taskExecution task.Execution) int {
+	if options := taskExecution.GetOptions(); options.RetryTimes != nil {
+		return *options.RetryTimes
+	}
+	return jr.config.RetryTimes
+}
+
+/* delay before retry (start at 1) by backoff of task */
+func (jr *jobRunner) getRetryDelay(taskExecution task.Execution, retry int) time.Duration {
+	options := taskExecution.GetOptions()
+	delay := jr.config.RetryDelay
+	if options.RetryDelay != nil {
+		delay = *options.RetryDelay
+	}
+	return options.GetBackoffDelay(delay, retry)
+}
+
+func (jr *jobRunner) getTaskTimeout(taskExecution task.Execution) time.Duration {
+	if options := taskExecution.GetOptions(); options.Timeout != nil {
+		return *options.Timeout
+	}
+	return jr.config.TaskTimeout
+}
+
+/* callback of task receive task and error by context */
+func (jr *jobRunner) callTaskCallback(taskExecution task.Execution, fn func(ctx context.Context) error, err error) {
+	if fn == nil {
+		return
+	}
+	ctx := context.WithValue(jr.ctx, constants.TASK_KEY, taskExecution)
+	if err != nil {
+		ctx = context.WithValue(ctx, constants.TASK_EXCEPTION_KEY, err)
+	}
+	if err := fn(ctx); err != nil {
+		log.Errorf("callback of task %s on job %s was failed with error: %s", taskExecution.GetId(), jr.id, err.Error())
+	}
+}
+
+/* sla of task is missed when task was not finished in duration, return func which stop watching */
+func (jr *jobRunner) watchSla(taskExecution task.Execution) func() {
+	options := taskExecution.GetOptions()
+	if options.SLA <= 0 {
+		return func() {}
+	}
+	timer := time.AfterFunc(options.SLA, func() {
+		jr.slaMissed.Store(taskExecution.GetId(), true)
+		jr.callTaskCallback(taskExecution, options.OnSLAMiss, nil)
+	})
+	return func() {
+		timer.Stop()
+	}
+}
+
+func (jr *jobRunner) isSlaMissed(taskExecution task.Execution) bool {
+	_, ok := jr.slaMissed.Load(taskExecution.GetId())
+	return ok
+}
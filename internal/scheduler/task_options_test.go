@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func newSleepTask(name string, sleep time.Duration, options task.TaskOptions) task.Execution {
+	return task.NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-time.After(sleep):
+			return name, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}), options)
+}
+
+func TestTaskRetryOption(t *testing.T) {
+	retryTimes, noRetry := 2, 0
+	var calls, noRetryCalls, retried, succeeded, failed int32
+	var exception error
+	flaky := newFlakyTask("flaky", 2, &calls)
+	flaky.SetOptions(task.TaskOptions{
+		RetryTimes: &retryTimes,
+		OnTaskRetry: func(ctx context.Context) error {
+			atomic.AddInt32(&retried, 1)
+			/* error of callback is logged and does not fail task */
+			return errors.New("callback was failed")
+		},
+		OnTaskSuccess: func(ctx context.Context) error {
+			atomic.AddInt32(&succeeded, 1)
+			return nil
+		},
+	})
+	once := newFlakyTask("once", 5, &noRetryCalls)
+	once.SetOptions(task.TaskOptions{
+		RetryTimes: &noRetry,
+		OnTaskFailure: func(ctx context.Context) error {
+			atomic.AddInt32(&failed, 1)
+			exception, _ = ctx.Value(constants.TASK_EXCEPTION_KEY).(error)
+			return nil
+		},
+	})
+	job := NewJob(nil)
+	job.AddTask(flaky, once)
+	job.SetDownstream(flaky, once)
+	config := NewDefaultSchedulerConfig()
+	config.RetryTimes = 5
+	config.RetryDelay = time.Millisecond
+
+	repository := runTestJobWithConfig(t, job, config)
+	if status := repository.tasks["flaky"].Status; status != constants.JOB_STATUS_SUCCESS || calls != 3 {
+		t.Fatalf("expected flaky success on third call, got %s after %d calls", status, calls)
+	}
+	if retried != 2 || succeeded != 1 {
+		t.Fatalf("expected 2 retry and 1 success callback, got %d and %d", retried, succeeded)
+	}
+	if status := repository.tasks["once"].Status; status != constants.JOB_STATUS_FAILED || noRetryCalls != 1 {
+		t.Fatalf("expected once failed without retry of scheduler, got %s after %d calls", status, noRetryCalls)
+	}
+	if failed != 1 || exception == nil || exception.Error() != "boom" {
+		t.Fatalf("expected failure callback with exception, got %d %v", failed, exception)
+	}
+}
+
+func TestTaskRetryDelayOption(t *testing.T) {
+	delay := 10 * time.Millisecond
+	job := NewJob(nil)
+	fixed := newNopTask("fixed")
+	exponential := newNopTask("exponential")
+	exponential.SetOptions(task.TaskOptions{RetryDelay: &delay, RetryBackoff: constants.RETRY_BACKOFF_EXPONENTIAL, MaxRetryDelay: 50 * time.Millisecond})
+	job.AddTask(fixed, exponential)
+	config := NewDefaultSchedulerConfig()
+	config.RetryDelay = time.Second
+	s := NewScheduler("", "test", "", config)
+	if err := s.RegisterJob(job); err != nil {
+		t.Fatal(err)
+	}
+	runner := newJobRunner(context.Background(), job, nil, nil)
+
+	cases := []struct {
+		task     task.Execution
+		retry    int
+		expected time.Duration
+	}{
+		{task: fixed, retry: 3, expected: time.Second},
+		{task: exponential, retry: 1, expected: 10 * time.Millisecond},
+		{task: exponential, retry: 3, expected: 40 * time.Millisecond},
+		{task: exponential, retry: 4, expected: 50 * time.Millisecond},
+	}
+	for _, tc := range cases {
+		if delay := runner.getRetryDelay(tc.task, tc.retry); delay != tc.expected {
+			t.Fatalf("expected delay of %s on retry %d is %s, got %s", tc.task.GetName(), tc.retry, tc.expected, delay)
+		}
+	}
+}
+
+func TestTaskTimeoutOption(t *testing.T) {
+	short, none := 20*time.Millisecond, time.Duration(0)
+	cases := []struct {
+		name    string
+		sleep   time.Duration
+		options task.TaskOptions
+		status  constants.JobStatus
+	}{
+		{name: "timeout of task is shorter", sleep: time.Second, options: task.TaskOptions{Timeout: &short}, status: constants.JOB_STATUS_TIMEOUT},
+		{name: "task has no deadline", sleep: 100 * time.Millisecond, options: task.TaskOptions{Timeout: &none}, status: constants.JOB_STATUS_SUCCESS},
+		{name: "timeout of scheduler", sleep: time.Second, options: task.TaskOptions{}, status: constants.JOB_STATUS_TIMEOUT},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := NewJob(nil)
+			job.AddTask(newSleepTask("a", tc.sleep, tc.options))
+			config := NewDefaultSchedulerConfig()
+			config.TaskTimeout = 50 * time.Millisecond
+
+			repository := runTestJobWithConfig(t, job, config)
+			if status := repository.tasks["a"].Status; status != tc.status {
+				t.Fatalf("expected %s, got %s", tc.status, status)
+			}
+		})
+	}
+}
+
+func TestTaskSla(t *testing.T) {
+	var missed int32
+	onSlaMiss := func(ctx context.Context) error {
+		if taskExecution, ok := ctx.Value(constants.TASK_KEY).(task.Execution); ok && taskExecution.GetName() == "slow" {
+			atomic.AddInt32(&missed, 1)
+		}
+		return nil
+	}
+	job := NewJob(nil)
+	job.AddTask(
+		newSleepTask("slow", 50*time.Millisecond, task.TaskOptions{SLA: 10 * time.Millisecond, OnSLAMiss: onSlaMiss}),
+		newSleepTask("fast", 0, task.TaskOptions{SLA: time.Second, OnSLAMiss: onSlaMiss}),
+	)
+
+	repository := runTestJob(t, job)
+	/* task which missed sla is still success */
+	if slow := repository.tasks["slow"]; !slow.SlaMissed || slow.Status != constants.JOB_STATUS_SUCCESS {
+		t.Fatalf("expected slow success with missed sla, got %s %v", slow.Status, slow.SlaMissed)
+	}
+	if fast := repository.tasks["fast"]; fast.SlaMissed {
+		t.Fatal("expected fast did not miss sla")
+	}
+	if missed := atomic.LoadInt32(&missed); missed != 1 {
+		t.Fatalf("expected sla miss callback once, got %d", missed)
+	}
+}
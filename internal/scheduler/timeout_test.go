@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/task"
+)
+
+func TestTimeout(t *testing.T) {
+	cases := []struct {
+		name        string
+		jobTimeout  time.Duration
+		taskTimeout time.Duration
+		handleCtx   bool
+	}{
+		{name: "task timeout", taskTimeout: 50 * time.Millisecond, handleCtx: true},
+		{name: "job timeout", jobTimeout: 50 * time.Millisecond, handleCtx: true},
+		{name: "task which not handle context", taskTimeout: 50 * time.Millisecond},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cancelled = make(chan struct{})
+			var release = make(chan struct{})
+			defer close(release)
+			slow := task.NewTask("slow", executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+				if !tc.handleCtx {
+					<-release
+					return nil, nil
+				}
+				select {
+				case <-ctx.Done():
+					close(cancelled)
+					return nil, ctx.Err()
+				case <-release:
+					return nil, nil
+				}
+			}))
+			after := newResultTask("after", nil)
+			job := NewJob(nil)
+			job.AddTask(slow, after)
+			job.SetDownstream(slow, after)
+			config := NewDefaultSchedulerConfig()
+			config.JobTimeout = tc.jobTimeout
+			config.TaskTimeout = tc.taskTimeout
+
+			start := time.Now()
+			repository := runTestJobWithConfig(t, job, config)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("job was not stopped by timeout, took %s", elapsed)
+			}
+			if tc.handleCtx {
+				select {
+				case <-cancelled:
+				case <-time.After(time.Second):
+					t.Fatal("context of task was not cancelled")
+				}
+			}
+			if status := repository.tasks["slow"].Status; status != constants.JOB_STATUS_TIMEOUT {
+				t.Errorf("expected task %s, got %s", constants.JOB_STATUS_TIMEOUT, status)
+			}
+			if status := repository.tasks["after"].Status; status != constants.JOB_STATUS_SKIPPED {
+				t.Errorf("expected downstream %s, got %s", constants.JOB_STATUS_SKIPPED, status)
+			}
+			if repository.job.Status != constants.JOB_STATUS_TIMEOUT {
+				t.Errorf("expected job %s, got %s", constants.JOB_STATUS_TIMEOUT, repository.job.Status)
+			}
+		})
+	}
+}
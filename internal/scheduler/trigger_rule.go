@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+func isFailedStatus(status constants.JobStatus) bool {
+	switch status {
+	case constants.JOB_STATUS_FAILED, constants.JOB_STATUS_TIMEOUT:
+		return true
+	}
+	return false
+}
+
+/* consider task should be run from status of all upstreams */
+func isTriggerRuleMatched(rule constants.TriggerRule, upstreamStatuses []constants.JobStatus) bool {
+	switch rule {
+	case constants.TRIGGER_RULE_ALL_DONE:
+		return true
+	case constants.TRIGGER_RULE_ONE_FAILED:
+		for _, status := range upstreamStatuses {
+			if isFailedStatus(status) {
+				return true
+			}
+		}
+		return false
+	case constants.TRIGGER_RULE_NONE_FAILED:
+		for _, status := range upstreamStatuses {
+			if isFailedStatus(status) {
+				return false
+			}
+		}
+		return true
+	default:
+		for _, status := range upstreamStatuses {
+			if status != constants.JOB_STATUS_SUCCESS {
+				return false
+			}
+		}
+		return true
+	}
+}
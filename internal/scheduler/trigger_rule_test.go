@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+func TestIsTriggerRuleMatched(t *testing.T) {
+	var (
+		success = constants.JOB_STATUS_SUCCESS
+		failed  = constants.JOB_STATUS_FAILED
+		timeout = constants.JOB_STATUS_TIMEOUT
+		skipped = constants.JOB_STATUS_SKIPPED
+	)
+	cases := []struct {
+		rule     constants.TriggerRule
+		statuses []constants.JobStatus
+		expected bool
+	}{
+		{constants.TRIGGER_RULE_ALL_SUCCESS, []constants.JobStatus{success, success}, true},
+		{constants.TRIGGER_RULE_ALL_SUCCESS, []constants.JobStatus{success, skipped}, false},
+		{constants.TRIGGER_RULE_ALL_SUCCESS, []constants.JobStatus{success, failed}, false},
+		{"", []constants.JobStatus{success}, true},
+		{"", []constants.JobStatus{skipped}, false},
+		{constants.TRIGGER_RULE_ALL_DONE, []constants.JobStatus{failed, skipped}, true},
+		{constants.TRIGGER_RULE_ONE_FAILED, []constants.JobStatus{success, failed}, true},
+		{constants.TRIGGER_RULE_ONE_FAILED, []constants.JobStatus{success, timeout}, true},
+		{constants.TRIGGER_RULE_ONE_FAILED, []constants.JobStatus{success, skipped}, false},
+		{constants.TRIGGER_RULE_NONE_FAILED, []constants.JobStatus{success, skipped}, true},
+		{constants.TRIGGER_RULE_NONE_FAILED, []constants.JobStatus{success, timeout}, false},
+		{constants.TRIGGER_RULE_ALL_SUCCESS, []constants.JobStatus{}, true},
+	}
+	for _, tc := range cases {
+		if actual := isTriggerRuleMatched(tc.rule, tc.statuses); actual != tc.expected {
+			t.Errorf("rule %q with %v: expected %v, got %v", tc.rule, tc.statuses, tc.expected, actual)
+		}
+	}
+}
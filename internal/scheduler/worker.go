@@ -0,0 +1,280 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/logger"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	default_worker_heartbeat_timeout = 30 * time.Second
+)
+
+/*
+worker claim task of scheduler on queue mode from database and call it on this node,
+task which was claimed by worker that stop heartbeat longer than heartbeat timeout is queued again
+*/
+type Worker struct {
+	nodeId           string
+	registry         *Registry
+	dbAdapter        connection.DatabaseAdapterConnection
+	concurrency      int
+	pollInterval     time.Duration
+	heartbeatTimeout time.Duration
+	mutex            *sync.Mutex
+	running          map[int64]context.CancelFunc // id of claimed task on queue
+	tasks            *sync.WaitGroup
+	stop             chan struct{} // stop claim new task
+	done             chan struct{} // stop loop after every running task was finished
+	stopped          chan struct{}
+}
+
+func NewWorker(nodeId string, registry *Registry, concurrency int, pollInterval time.Duration, heartbeatTimeout time.Duration) *Worker {
+	if pollInterval <= 0 {
+		pollInterval = default_queue_poll_interval
+	}
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = default_worker_heartbeat_timeout
+	}
+	return &Worker{
+		nodeId:           nodeId,
+		registry:         registry,
+		concurrency:      concurrency,
+		pollInterval:     pollInterval,
+		heartbeatTimeout: heartbeatTimeout,
+		mutex:            new(sync.Mutex),
+		running:          make(map[int64]context.CancelFunc),
+		tasks:            new(sync.WaitGroup),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+	}
+}
+
+func (w *Worker) GetNodeId() string {
+	return w.nodeId
+}
+
+func (w *Worker) Start(dbAdapter connection.DatabaseAdapterConnection) {
+	w.dbAdapter = dbAdapter
+	go w.loop()
+}
+
+/*
+stop claim new task and wait until running task was finished or ctx was done, heartbeat is kept during waiting.
+task which is still running after ctx was done is queued again for another worker before it is cancelled
+*/
+func (w *Worker) Shutdown(ctx context.Context) {
+	close(w.stop)
+	done := make(chan struct{})
+	go func() {
+		w.tasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.handBack()
+		<-done
+	}
+	close(w.done)
+	<-w.stopped
+}
+
+func (w *Worker) handBack() {
+	w.mutex.Lock()
+	var ids = make([]int64, 0, len(w.running))
+	for id := range w.running {
+		ids = append(ids, id)
+	}
+	w.mutex.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	if err := w.dbAdapter.GetRepository().RequeueTasks(context.Background(), w.nodeId, ids); err != nil {
+		log.Errorf("failed to requeue running task with error: %s", err.Error())
+	} else {
+		log.Infof("requeue %d running task of node %s by shutdown", len(ids), w.nodeId)
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, id := range ids {
+		if cancel, ok := w.running[id]; ok {
+			cancel()
+		}
+	}
+}
+
+func (w *Worker) loop() {
+	defer close(w.stopped)
+	poll := time.NewTicker(w.pollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(w.heartbeatTimeout / 3)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-heartbeat.C:
+			w.heartbeat()
+		case <-poll.C:
+			select {
+			case <-w.stop:
+				continue
+			default:
+			}
+			w.requeue()
+			w.claim()
+		}
+	}
+}
+
+/* scheduler on queue mode which was registered on this node */
+func (w *Worker) getSchedulerNames() []string {
+	var names = make([]string, 0)
+	for _, schedulerInstance := range w.registry.List() {
+		if schedulerInstance.config.getExecutionMode() == constants.EXECUTION_MODE_QUEUE {
+			names = append(names, schedulerInstance.GetName())
+		}
+	}
+	return names
+}
+
+func (w *Worker) requeue() {
+	count, err := w.dbAdapter.GetRepository().RequeueStaleTasks(context.Background(), w.heartbeatTimeout)
+	if err != nil {
+		log.Errorf("failed to requeue stale task with error: %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("requeue %d task which heartbeat was stale", count)
+	}
+}
+
+func (w *Worker) claim() {
+	w.mutex.Lock()
+	free := w.concurrency - len(w.running)
+	w.mutex.Unlock()
+	if free <= 0 {
+		return
+	}
+
+	items, err := w.dbAdapter.GetRepository().ClaimTasks(context.Background(), w.nodeId, w.getSchedulerNames(), free)
+	if err != nil {
+		log.Errorf("failed to claim task with error: %s", err.Error())
+		return
+	}
+	for _, item := range items {
+		ctx, cancel := context.WithCancel(context.Background())
+		w.mutex.Lock()
+		w.running[item.Id] = cancel
+		w.mutex.Unlock()
+
+		w.tasks.Add(1)
+		go w.execute(ctx, item)
+	}
+}
+
+/* task which was cancelled by job or reclaimed by another worker is cancelled on this node */
+func (w *Worker) heartbeat() {
+	w.mutex.Lock()
+	var ids = make([]int64, 0, len(w.running))
+	for id := range w.running {
+		ids = append(ids, id)
+	}
+	w.mutex.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	owned, err := w.dbAdapter.GetRepository().HeartbeatTasks(context.Background(), w.nodeId, ids)
+	if err != nil {
+		log.Errorf("failed to heartbeat task with error: %s", err.Error())
+		return
+	}
+	var isOwned = make(map[int64]bool, len(owned))
+	for _, id := range owned {
+		isOwned[id] = true
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, id := range ids {
+		if cancel, ok := w.running[id]; ok && !isOwned[id] {
+			cancel()
+		}
+	}
+}
+
+func (w *Worker) release(id int64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if cancel, ok := w.running[id]; ok {
+		cancel()
+		delete(w.running, id)
+	}
+}
+
+func (w *Worker) execute(ctx context.Context, item *models.TaskQueue) {
+	defer w.tasks.Done()
+	defer w.release(item.Id)
+
+	value, parameter, err := w.call(ctx, item)
+	item.Status = constants.QUEUE_STATUS_SUCCESS
+	item.Result = value
+	item.Parameter = parameter
+	item.UpdatedAt = time.Now()
+	if err != nil {
+		exception := newRunnerException(err, true)
+		item.Status = constants.QUEUE_STATUS_FAILED
+		item.TaskException = exception.Error()
+		item.StackTrace = exception.StackTrace()
+	}
+	if err := w.dbAdapter.GetRepository().FinishTask(context.Background(), item); err != nil {
+		log.Errorf("failed to finish task %s of job %s on queue with error: %s", item.TaskId, item.JobId, err.Error())
+	}
+}
+
+/* return serialized value and parameter of job after task was called */
+func (w *Worker) call(ctx context.Context, item *models.TaskQueue) (string, string, error) {
+	schedulerInstance := w.registry.Get(item.SchedulerName)
+	if schedulerInstance == nil || schedulerInstance.jobInstance == nil {
+		return "", "", fmt.Errorf("scheduler %s was not registered on node %s", item.SchedulerName, w.nodeId)
+	}
+	taskExecution := findTask(schedulerInstance.jobInstance.tasks, item.TaskId)
+	if taskExecution == nil {
+		return "", "", fmt.Errorf("task %s was not found in scheduler %s on node %s", item.TaskId, item.SchedulerName, w.nodeId)
+	}
+	var payload queuePayload
+	if err := json.Unmarshal([]byte(item.Payload), &payload); err != nil {
+		return "", "", err
+	}
+
+	runner := newQueueRunner(ctx, schedulerInstance.jobInstance, item, payload)
+	runner.currentTask = taskExecution
+	runner.logger = logger.NewLoggerWithFile(constants.LOG_PATH_RUNNER_TASK(runner.schedulerName, runner.executeDatetime, taskExecution.GetName()))
+
+	value, err := runner.call(taskExecution, taskExecution.Call)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = context.DeadlineExceeded
+		}
+		return "", runner.encodeParameter(), err
+	}
+	/* value is sent back to node which run job by database */
+	data, err := runner.serializeTaskValue(value)
+	if err != nil {
+		return "", runner.encodeParameter(), fmt.Errorf("value of task %s can not be sent from worker: %s", item.TaskId, err.Error())
+	}
+	return data, runner.encodeParameter(), nil
+}
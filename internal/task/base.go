@@ -10,24 +10,66 @@ import (
 type Execution interface {
 	GetType() constants.TaskType
 	GetName() string
+	GetId() string // fully qualified id of task in job, it is assigned when job was registered
 	GetExecutionName() string
+	GetTriggerRule() constants.TriggerRule
+	SetTriggerRule(rule constants.TriggerRule)
+	GetOptions() TaskOptions
+	SetOptions(options TaskOptions)
 	Call(ctx context.Context) (interface{}, error)
 	MarshalJSON() ([]byte, error)
 }
 
 type taskbase struct {
-	taskType constants.TaskType
-	name     string
+	taskType    constants.TaskType
+	name        string
+	triggerRule constants.TriggerRule
+	group       string // path of task group which task was embedded, such as group.subgroup
+	id          string // path of task in branch, such as branch/pipeline/task#2
+	options     TaskOptions
 }
 
 func (s taskbase) MarshalJSON() ([]byte, error) {
 	type ptr struct {
-		TaskType string `json:"type"`
-		Name     string `json:"name"`
+		TaskType    string `json:"type"`
+		Name        string `json:"name"`
+		TriggerRule string `json:"trigger_rule"`
 	}
 	sh := ptr{
-		TaskType: string(s.taskType),
-		Name:     s.name,
+		TaskType:    string(s.taskType),
+		Name:        s.name,
+		TriggerRule: string(s.GetTriggerRule()),
 	}
 	return json.Marshal(sh)
 }
+
+func (s taskbase) GetTriggerRule() constants.TriggerRule {
+	if s.triggerRule == "" {
+		return constants.TRIGGER_RULE_ALL_SUCCESS
+	}
+	return s.triggerRule
+}
+
+func (s *taskbase) SetTriggerRule(rule constants.TriggerRule) {
+	s.triggerRule = rule
+}
+
+func (s taskbase) GetGroup() string {
+	return s.group
+}
+
+/* id is name of task when job was not registered */
+func (s taskbase) GetId() string {
+	if s.id == "" {
+		return s.name
+	}
+	return s.id
+}
+
+func (s taskbase) GetOptions() TaskOptions {
+	return s.options
+}
+
+func (s *taskbase) SetOptions(options TaskOptions) {
+	s.options = options
+}
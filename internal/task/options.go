@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+/*
+option of each task which override config of scheduler, nil is config of scheduler.
+callback can read task by constants.TASK_KEY and error by constants.TASK_EXCEPTION_KEY from context
+*/
+type TaskOptions struct {
+	RetryTimes    *int
+	RetryDelay    *time.Duration         // delay of first retry
+	RetryBackoff  constants.RetryBackoff // default is fixed
+	MaxRetryDelay time.Duration          // limit of exponential and jitter delay, 0 is no limit
+	Timeout       *time.Duration         // deadline of each call of task, 0 is no deadline
+	SLA           time.Duration          // task should be finished in duration since it was started, 0 is no sla
+	OnTaskSuccess func(ctx context.Context) error
+	OnTaskFailure func(ctx context.Context) error
+	OnTaskRetry   func(ctx context.Context) error
+	OnSLAMiss     func(ctx context.Context) error
+}
+
+func (o TaskOptions) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		RetryTimes    *int    `json:"retry_times"`
+		RetryDelay    *string `json:"retry_delay"`
+		RetryBackoff  string  `json:"retry_backoff"`
+		MaxRetryDelay string  `json:"max_retry_delay"`
+		Timeout       *string `json:"timeout"`
+		SLA           string  `json:"sla"`
+		OnTaskSuccess bool    `json:"is_handle_on_task_success"`
+		OnTaskFailure bool    `json:"is_handle_on_task_failure"`
+		OnTaskRetry   bool    `json:"is_handle_on_task_retry"`
+		OnSLAMiss     bool    `json:"is_handle_on_sla_miss"`
+	}
+	var sh = ptr{
+		RetryTimes:    o.RetryTimes,
+		RetryBackoff:  string(o.GetRetryBackoff()),
+		MaxRetryDelay: o.MaxRetryDelay.String(),
+		SLA:           o.SLA.String(),
+		OnTaskSuccess: o.OnTaskSuccess != nil,
+		OnTaskFailure: o.OnTaskFailure != nil,
+		OnTaskRetry:   o.OnTaskRetry != nil,
+		OnSLAMiss:     o.OnSLAMiss != nil,
+	}
+	if o.RetryDelay != nil {
+		delay := o.RetryDelay.String()
+		sh.RetryDelay = &delay
+	}
+	if o.Timeout != nil {
+		timeout := o.Timeout.String()
+		sh.Timeout = &timeout
+	}
+	return json.Marshal(sh)
+}
+
+func (o TaskOptions) GetRetryBackoff() constants.RetryBackoff {
+	if o.RetryBackoff == "" {
+		return constants.RETRY_BACKOFF_FIXED
+	}
+	return o.RetryBackoff
+}
+
+/* delay before retry (start at 1) by backoff of task */
+func (o TaskOptions) GetBackoffDelay(delay time.Duration, retry int) time.Duration {
+	if o.GetRetryBackoff() == constants.RETRY_BACKOFF_FIXED || delay <= 0 {
+		return delay
+	}
+	for i := 1; i < retry; i++ {
+		if (o.MaxRetryDelay > 0 && delay >= o.MaxRetryDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if o.MaxRetryDelay > 0 && delay > o.MaxRetryDelay {
+		delay = o.MaxRetryDelay
+	}
+	if o.GetRetryBackoff() == constants.RETRY_BACKOFF_JITTER {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+	return delay
+}
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+)
+
+func TestGetBackoffDelay(t *testing.T) {
+	cases := []struct {
+		name     string
+		options  TaskOptions
+		delay    time.Duration
+		retry    int
+		expected time.Duration
+	}{
+		{name: "fixed", options: TaskOptions{}, delay: time.Second, retry: 3, expected: time.Second},
+		{name: "exponential first retry", options: TaskOptions{RetryBackoff: constants.RETRY_BACKOFF_EXPONENTIAL}, delay: time.Second, retry: 1, expected: time.Second},
+		{name: "exponential", options: TaskOptions{RetryBackoff: constants.RETRY_BACKOFF_EXPONENTIAL}, delay: time.Second, retry: 4, expected: 8 * time.Second},
+		{name: "exponential over max", options: TaskOptions{RetryBackoff: constants.RETRY_BACKOFF_EXPONENTIAL, MaxRetryDelay: 5 * time.Second}, delay: time.Second, retry: 4, expected: 5 * time.Second},
+		{name: "exponential without overflow", options: TaskOptions{RetryBackoff: constants.RETRY_BACKOFF_EXPONENTIAL}, delay: time.Hour, retry: 100, expected: (1 << 21) * time.Hour},
+		{name: "no delay", options: TaskOptions{RetryBackoff: constants.RETRY_BACKOFF_EXPONENTIAL}, delay: 0, retry: 3, expected: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if delay := tc.options.GetBackoffDelay(tc.delay, tc.retry); delay != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, delay)
+			}
+		})
+	}
+}
+
+func TestGetBackoffDelayJitter(t *testing.T) {
+	options := TaskOptions{RetryBackoff: constants.RETRY_BACKOFF_JITTER, MaxRetryDelay: 3 * time.Second}
+	for i := 0; i < 100; i++ {
+		if delay := options.GetBackoffDelay(time.Second, 3); delay < 0 || delay > 3*time.Second {
+			t.Fatalf("expected jitter delay between 0 and max retry delay, got %s", delay)
+		}
+	}
+}
@@ -13,26 +13,40 @@ type Task struct {
 	fn       executor.Execution `json:"-"`
 }
 
-func NewTask(name string, execution executor.Execution) Execution {
-	return &Task{
+/* options override retry, timeout and callback of scheduler config */
+func NewTask(name string, execution executor.Execution, options ...TaskOptions) Execution {
+	t := &Task{
 		taskbase: taskbase{
-			taskType: constants.TASK_TYPE_BASE_TASK,
-			name:     name,
+			taskType:    constants.TASK_TYPE_BASE_TASK,
+			name:        name,
+			triggerRule: constants.TRIGGER_RULE_ALL_SUCCESS,
 		},
 		fn: execution,
 	}
+	if len(options) > 0 {
+		t.options = options[0]
+	}
+	return t
 }
 
 func (s Task) MarshalJSON() ([]byte, error) {
 	type ptr struct {
-		TaskType      string `json:"type"`
-		Name          string `json:"name"`
-		ExecutionName string `json:"execution_name"`
+		TaskType      string      `json:"type"`
+		Name          string      `json:"name"`
+		Id            string      `json:"id"`
+		ExecutionName string      `json:"execution_name"`
+		TriggerRule   string      `json:"trigger_rule"`
+		Group         string      `json:"group,omitempty"`
+		Options       TaskOptions `json:"options"`
 	}
 	sh := ptr{
 		TaskType:      string(s.taskbase.taskType),
 		Name:          s.taskbase.name,
+		Id:            s.taskbase.GetId(),
 		ExecutionName: s.fn.GetName(),
+		TriggerRule:   string(s.taskbase.GetTriggerRule()),
+		Group:         s.taskbase.group,
+		Options:       s.taskbase.options,
 	}
 	return json.Marshal(sh)
 }
@@ -9,37 +9,57 @@ import (
 
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
-	"github.com/spf13/cast"
 )
 
+/*
+branch task select pipeline by name which executor return, executor may return list of name for run many pipelines concurrently.
+pipeline which was not selected is skipped
+*/
 type TaskBranch struct {
 	taskbase    `json:",inline"`
 	fn          executor.Execution
 	taskBranchs []TaskBranchPipeLine
+	joinMode    constants.BranchJoinMode
 }
 
-func NewTaskBranch(name string, execution executor.Execution, tasks []TaskBranchPipeLine) Execution {
-	return &TaskBranch{
+func NewTaskBranch(name string, execution executor.Execution, tasks []TaskBranchPipeLine, options ...TaskOptions) Execution {
+	t := &TaskBranch{
 		taskbase: taskbase{
-			taskType: constants.TASK_TYPE_BRANCH_TASK,
-			name:     name,
+			taskType:    constants.TASK_TYPE_BRANCH_TASK,
+			name:        name,
+			triggerRule: constants.TRIGGER_RULE_ALL_SUCCESS,
 		},
 		fn:          execution,
 		taskBranchs: tasks,
+		joinMode:    constants.BRANCH_JOIN_MODE_ALL,
 	}
+	if len(options) > 0 {
+		t.options = options[0]
+	}
+	return t
 }
 
 func (s TaskBranch) MarshalJSON() ([]byte, error) {
 	type ptr struct {
 		TaskType      string              `json:"type"`
 		Name          string              `json:"name"`
+		Id            string              `json:"id"`
 		ExecutionName string              `json:"execution_name"`
+		TriggerRule   string              `json:"trigger_rule"`
+		Group         string              `json:"group,omitempty"`
+		Options       TaskOptions         `json:"options"`
+		JoinMode      string              `json:"join_mode"`
 		TaskBranchs   TaskBranchPipeLines `json:"task_branchs"`
 	}
 	sh := ptr{
 		TaskType:      string(s.taskbase.taskType),
 		Name:          string(s.taskbase.name),
+		Id:            s.taskbase.GetId(),
 		ExecutionName: s.fn.GetName(),
+		TriggerRule:   string(s.taskbase.GetTriggerRule()),
+		Group:         s.taskbase.group,
+		Options:       s.taskbase.options,
+		JoinMode:      string(s.GetJoinMode()),
 		TaskBranchs:   s.taskBranchs,
 	}
 	return json.Marshal(sh)
@@ -57,20 +77,59 @@ func (t TaskBranch) GetExecutionName() string {
 	return t.fn.GetName()
 }
 
+func (t TaskBranch) GetJoinMode() constants.BranchJoinMode {
+	if t.joinMode == "" {
+		return constants.BRANCH_JOIN_MODE_ALL
+	}
+	return t.joinMode
+}
+
+func (t *TaskBranch) SetJoinMode(mode constants.BranchJoinMode) {
+	t.joinMode = mode
+}
+
+/* return selected pipelines */
 func (t TaskBranch) Call(ctx context.Context) (interface{}, error) {
-	taskname, err := t.fn.Execute(ctx)
+	tasknames, err := t.fn.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if reflect.TypeOf(taskname).Kind() != reflect.String {
-		return nil, errors.New("TaskBranch must be return taskanme on type string")
+	return t.SelectPipelines(tasknames)
+}
+
+/* select pipeline by name or list of name, duplicated name is selected once */
+func (t TaskBranch) SelectPipelines(tasknames interface{}) (TaskBranchPipeLines, error) {
+	var names = make([]string, 0)
+	switch value := tasknames.(type) {
+	case string:
+		names = append(names, value)
+	case []string:
+		names = append(names, value...)
+	default:
+		rv := reflect.ValueOf(tasknames)
+		if tasknames == nil || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+			return nil, errors.New("TaskBranch must be return taskanme on type string or list of string")
+		}
+		for i := 0; i < rv.Len(); i++ {
+			name, ok := rv.Index(i).Interface().(string)
+			if !ok {
+				return nil, errors.New("TaskBranch must be return taskanme on type string or list of string")
+			}
+			names = append(names, name)
+		}
 	}
-	for _, task := range t.taskBranchs {
-		if cast.ToString(taskname) == task.name {
-			return task, nil
+
+	var pipelines = make(TaskBranchPipeLines, 0, len(names))
+	for _, name := range names {
+		pipeline, ok := t.GetPipeline(name)
+		if !ok {
+			return nil, fmt.Errorf("Task %s not found in TaskBranch", name)
+		}
+		if _, ok := pipelines.Get(name); !ok {
+			pipelines = append(pipelines, pipeline)
 		}
 	}
-	return nil, fmt.Errorf("Task %s not found in TaskBranch", cast.ToString(taskname))
+	return pipelines, nil
 }
 
 type TaskBranchPipeLine struct {
@@ -138,3 +197,34 @@ func (t TaskBranchPipeLine) GetName() string {
 func (t TaskBranchPipeLine) GetTasks() []Execution {
 	return t.tasks
 }
+
+/* every pipeline of branch */
+func (t TaskBranch) GetPipelines() TaskBranchPipeLines {
+	return t.taskBranchs
+}
+
+func (s TaskBranchPipeLines) Get(name string) (TaskBranchPipeLine, bool) {
+	for _, pipeline := range s {
+		if pipeline.name == name {
+			return pipeline, true
+		}
+	}
+	return TaskBranchPipeLine{}, false
+}
+
+func (s TaskBranchPipeLines) GetNames() []string {
+	var names = make([]string, 0, len(s))
+	for _, pipeline := range s {
+		names = append(names, pipeline.name)
+	}
+	return names
+}
+
+func (t TaskBranch) GetPipeline(name string) (TaskBranchPipeLine, bool) {
+	for _, pipeline := range t.taskBranchs {
+		if pipeline.name == name {
+			return pipeline, true
+		}
+	}
+	return TaskBranchPipeLine{}, false
+}
@@ -0,0 +1,252 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type GroupEdge struct {
+	Upstream   Execution
+	Downstream Execution
+}
+
+/*
+named set of task which has dependency inside group, group is reusable on many job.
+task is copied when group was embedded in job and name of task is prefixed by path of group (group.task).
+group which has not any dependency will run task by order of AddTask and then subgroup by order of AddGroup
+*/
+type TaskGroup struct {
+	name   string
+	tasks  []Execution
+	groups []*TaskGroup
+	edges  []GroupEdge
+}
+
+func NewTaskGroup(name string) *TaskGroup {
+	return &TaskGroup{
+		name:   name,
+		tasks:  make([]Execution, 0),
+		groups: make([]*TaskGroup, 0),
+		edges:  make([]GroupEdge, 0),
+	}
+}
+
+/* name of every task and subgroup in hierarchy */
+func (g TaskGroup) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		Name   string       `json:"name"`
+		Tasks  []string     `json:"tasks"`
+		Groups []*TaskGroup `json:"groups"`
+	}
+	var sh = ptr{
+		Name:   g.name,
+		Tasks:  make([]string, 0, len(g.tasks)),
+		Groups: g.groups,
+	}
+	for _, task := range g.tasks {
+		sh.Tasks = append(sh.Tasks, task.GetName())
+	}
+	return json.Marshal(sh)
+}
+
+func (g *TaskGroup) GetName() string {
+	return g.name
+}
+
+func (g *TaskGroup) AddTask(tasks ...Execution) {
+	g.tasks = append(g.tasks, tasks...)
+}
+
+func (g *TaskGroup) AddGroup(groups ...*TaskGroup) {
+	g.groups = append(g.groups, groups...)
+}
+
+/* upstream >> downstreams, task of subgroup is able to use by GetRoots or GetLeaves of subgroup */
+func (g *TaskGroup) SetDownstream(upstream Execution, downstreams ...Execution) {
+	for _, downstream := range downstreams {
+		g.edges = append(g.edges, GroupEdge{Upstream: upstream, Downstream: downstream})
+	}
+}
+
+/* upstreams >> downstream */
+func (g *TaskGroup) SetUpstream(downstream Execution, upstreams ...Execution) {
+	for _, upstream := range upstreams {
+		g.edges = append(g.edges, GroupEdge{Upstream: upstream, Downstream: downstream})
+	}
+}
+
+func (g *TaskGroup) GetGroups() []*TaskGroup {
+	return g.groups
+}
+
+/* every task in group and subgroup */
+func (g *TaskGroup) GetTasks() []Execution {
+	var tasks = make([]Execution, 0, len(g.tasks))
+	tasks = append(tasks, g.tasks...)
+	for _, group := range g.groups {
+		tasks = append(tasks, group.GetTasks()...)
+	}
+	return tasks
+}
+
+/* edge of task in this group, task and subgroup are chained by order when group has not any dependency */
+func (g *TaskGroup) getOwnEdges() []GroupEdge {
+	if len(g.edges) > 0 {
+		return g.edges
+	}
+	var edges = make([]GroupEdge, 0)
+	var previous []Execution
+	var chain = func(roots []Execution, leaves []Execution) {
+		for _, upstream := range previous {
+			for _, downstream := range roots {
+				edges = append(edges, GroupEdge{Upstream: upstream, Downstream: downstream})
+			}
+		}
+		previous = leaves
+	}
+	for _, task := range g.tasks {
+		chain([]Execution{task}, []Execution{task})
+	}
+	for _, group := range g.groups {
+		chain(group.GetRoots(), group.GetLeaves())
+	}
+	return edges
+}
+
+/* every edge in group and subgroup */
+func (g *TaskGroup) GetEdges() []GroupEdge {
+	var edges = make([]GroupEdge, 0, len(g.edges))
+	edges = append(edges, g.getOwnEdges()...)
+	for _, group := range g.groups {
+		edges = append(edges, group.GetEdges()...)
+	}
+	return edges
+}
+
+/* task which has not any upstream in group */
+func (g *TaskGroup) GetRoots() []Execution {
+	var hasUpstream = make(map[Execution]bool)
+	for _, edge := range g.GetEdges() {
+		hasUpstream[edge.Downstream] = true
+	}
+	var roots = make([]Execution, 0)
+	for _, task := range g.GetTasks() {
+		if !hasUpstream[task] {
+			roots = append(roots, task)
+		}
+	}
+	return roots
+}
+
+/* task which has not any downstream in group */
+func (g *TaskGroup) GetLeaves() []Execution {
+	var hasDownstream = make(map[Execution]bool)
+	for _, edge := range g.GetEdges() {
+		hasDownstream[edge.Upstream] = true
+	}
+	var leaves = make([]Execution, 0)
+	for _, task := range g.GetTasks() {
+		if !hasDownstream[task] {
+			leaves = append(leaves, task)
+		}
+	}
+	return leaves
+}
+
+/* copy of group which every task is renamed by path of group, it is used for add group in job */
+func (g *TaskGroup) Embed() (*TaskGroup, error) {
+	embedded, _, err := g.embed("")
+	return embedded, err
+}
+
+func (g *TaskGroup) embed(parent string) (*TaskGroup, map[Execution]Execution, error) {
+	var path = g.name
+	if parent != "" {
+		path = parent + "." + g.name
+	}
+	var embedded = NewTaskGroup(g.name)
+	var copies = make(map[Execution]Execution)
+	var locals = make(map[string]bool)
+	for _, task := range g.tasks {
+		locals[task.GetName()] = true
+	}
+
+	for _, task := range g.tasks {
+		if _, ok := copies[task]; ok {
+			return nil, nil, fmt.Errorf("task %s was added in group %s more than once", task.GetName(), path)
+		}
+		copied, err := copyTask(task, path, locals)
+		if err != nil {
+			return nil, nil, err
+		}
+		copies[task] = copied
+		embedded.tasks = append(embedded.tasks, copied)
+	}
+	for _, group := range g.groups {
+		subgroup, subcopies, err := group.embed(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for original, copied := range subcopies {
+			copies[original] = copied
+		}
+		embedded.groups = append(embedded.groups, subgroup)
+	}
+
+	for _, edge := range g.getOwnEdges() {
+		upstream, ok := copies[edge.Upstream]
+		if !ok {
+			return nil, nil, fmt.Errorf("task %s is not added in group %s", edge.Upstream.GetName(), path)
+		}
+		downstream, ok := copies[edge.Downstream]
+		if !ok {
+			return nil, nil, fmt.Errorf("task %s is not added in group %s", edge.Downstream.GetName(), path)
+		}
+		embedded.edges = append(embedded.edges, GroupEdge{Upstream: upstream, Downstream: downstream})
+	}
+	return embedded, copies, nil
+}
+
+/* copy task with name which is prefixed by path of group, upstream of mapped task in same group is prefixed too */
+func copyTask(execution Execution, path string, locals map[string]bool) (Execution, error) {
+	var prefix = path + "."
+	switch t := execution.(type) {
+	case *Task:
+		copied := *t
+		copied.name, copied.group = prefix+t.name, path
+		return &copied, nil
+	case *TaskSensor:
+		copied := *t
+		copied.name, copied.group = prefix+t.name, path
+		return &copied, nil
+	case *TaskShortCircuit:
+		copied := *t
+		copied.name, copied.group = prefix+t.name, path
+		return &copied, nil
+	case *TaskMapped:
+		copied := *t
+		copied.name, copied.group = prefix+t.name, path
+		copied.upstreamId = ""
+		if locals[t.config.Upstream] {
+			copied.config.Upstream = prefix + t.config.Upstream
+		}
+		return &copied, nil
+	case *TaskBranch:
+		copied := *t
+		copied.name, copied.group = prefix+t.name, path
+		copied.taskBranchs = make([]TaskBranchPipeLine, 0, len(t.taskBranchs))
+		for _, pipeline := range t.taskBranchs {
+			var tasks = make([]Execution, 0, len(pipeline.tasks))
+			for _, task := range pipeline.tasks {
+				copiedTask, err := copyTask(task, path, locals)
+				if err != nil {
+					return nil, err
+				}
+				tasks = append(tasks, copiedTask)
+			}
+			copied.taskBranchs = append(copied.taskBranchs, TaskBranchPipeLine{name: pipeline.name, tasks: tasks})
+		}
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("task %s can not be added in group %s", execution.GetName(), path)
+}
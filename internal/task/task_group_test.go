@@ -0,0 +1,96 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+)
+
+func getNames(tasks []Execution) []string {
+	var names = make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		names = append(names, task.GetName())
+	}
+	return names
+}
+
+func TestEmbedGroup(t *testing.T) {
+	extract, transform := newNopTask("extract"), newNopTask("transform")
+	external := NewTaskMapped("external", executor.NewGolangExecuter(nil), MappedConfig{Upstream: "start"})
+	local := NewTaskMapped("local", executor.NewGolangExecuter(nil), MappedConfig{Upstream: "write"})
+	write := newNopTask("write")
+	load := NewTaskGroup("load")
+	load.AddTask(write, local)
+
+	etl := NewTaskGroup("etl")
+	etl.AddTask(extract, transform, external)
+	etl.AddGroup(load)
+	etl.SetDownstream(extract, transform, external)
+
+	embedded, err := etl.Embed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := getNames(embedded.GetTasks())
+	expected := []string{"etl.extract", "etl.transform", "etl.external", "etl.load.write", "etl.load.local"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+	if group := embedded.GetTasks()[3].(*Task).GetGroup(); group != "etl.load" {
+		t.Fatalf("expected group path etl.load, got %s", group)
+	}
+
+	/* upstream of mapped task in same group is prefixed, upstream outside group is kept */
+	tasks := embedded.GetTasks()
+	if upstream := tasks[2].(*TaskMapped).GetUpstream(); upstream != "start" {
+		t.Fatalf("expected upstream outside group is kept, got %s", upstream)
+	}
+	if upstream := tasks[4].(*TaskMapped).GetUpstream(); upstream != "etl.load.write" {
+		t.Fatalf("expected upstream in group is prefixed, got %s", upstream)
+	}
+
+	/* edge is moved to copy of task, subgroup without dependency is chained by order of add task */
+	var edges = make([]string, 0)
+	for _, edge := range embedded.GetEdges() {
+		edges = append(edges, edge.Upstream.GetName()+">>"+edge.Downstream.GetName())
+	}
+	expectedEdges := []string{"etl.extract>>etl.transform", "etl.extract>>etl.external", "etl.load.write>>etl.load.local"}
+	if strings.Join(edges, ",") != strings.Join(expectedEdges, ",") {
+		t.Fatalf("expected edges %v, got %v", expectedEdges, edges)
+	}
+	if roots := getNames(embedded.GetRoots()); strings.Join(roots, ",") != "etl.extract,etl.load.write" {
+		t.Fatalf("unexpected roots %v", roots)
+	}
+	if leaves := getNames(embedded.GetLeaves()); strings.Join(leaves, ",") != "etl.transform,etl.external,etl.load.local" {
+		t.Fatalf("unexpected leaves %v", leaves)
+	}
+
+	/* original group is not changed and can be embedded again */
+	if extract.GetName() != "extract" || extract.(*Task).GetGroup() != "" {
+		t.Fatalf("expected original task was not changed, got %s in %s", extract.GetName(), extract.(*Task).GetGroup())
+	}
+	again, err := etl.Embed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.GetTasks()[0] == embedded.GetTasks()[0] {
+		t.Fatal("expected every embed copy task")
+	}
+}
+
+func TestEmbedGroupInvalid(t *testing.T) {
+	duplicated := NewTaskGroup("duplicated")
+	task := newNopTask("a")
+	duplicated.AddTask(task, task)
+	if _, err := duplicated.Embed(); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("expected duplicated task error, got %v", err)
+	}
+
+	outside := NewTaskGroup("outside")
+	outside.AddTask(newNopTask("a"))
+	outside.SetDownstream(outside.tasks[0], newNopTask("b"))
+	if _, err := outside.Embed(); err == nil || !strings.Contains(err.Error(), "is not added in group outside") {
+		t.Fatalf("expected task outside group error, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"fmt"
+)
+
+/*
+assign id of every task in job by path of branch and pipeline (branch/pipeline/task),
+task which has duplicated name in same pipeline is suffixed by order (task#2)
+*/
+func AssignIds(tasks []Execution) error {
+	return assignIds("", tasks, make(map[Execution]bool), make(map[string]bool))
+}
+
+func assignIds(prefix string, tasks []Execution, visited map[Execution]bool, ids map[string]bool) error {
+	var counts = make(map[string]int)
+	var siblings = make(map[string]string) // id of first task by name in same pipeline
+	for _, execution := range tasks {
+		if visited[execution] {
+			return fmt.Errorf("task %s was added in job more than once", execution.GetName())
+		}
+		visited[execution] = true
+
+		counts[execution.GetName()]++
+		id := prefix + execution.GetName()
+		if count := counts[execution.GetName()]; count > 1 {
+			id = fmt.Sprintf("%s#%d", id, count)
+		}
+		if ids[id] {
+			return fmt.Errorf("task id %s is duplicated", id)
+		}
+		ids[id] = true
+		if _, ok := siblings[execution.GetName()]; !ok {
+			siblings[execution.GetName()] = id
+		}
+
+		switch t := execution.(type) {
+		case *Task:
+			t.id = id
+		case *TaskSensor:
+			t.id = id
+		case *TaskMapped:
+			t.id = id
+		case *TaskShortCircuit:
+			t.id = id
+		case *TaskBranch:
+			t.id = id
+			for _, pipeline := range t.taskBranchs {
+				if err := assignIds(id+"/"+pipeline.name+"/", pipeline.tasks, visited, ids); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	/* upstream of mapped task is task in same pipeline */
+	for _, execution := range tasks {
+		if mapped, ok := execution.(*TaskMapped); ok {
+			mapped.upstreamId = mapped.config.Upstream
+			if id, ok := siblings[mapped.config.Upstream]; ok {
+				mapped.upstreamId = id
+			}
+		}
+	}
+	return nil
+}
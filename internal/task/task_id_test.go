@@ -0,0 +1,67 @@
+package task
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+)
+
+func newNopTask(name string) Execution {
+	return NewTask(name, executor.NewGolangExecuter(func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	}))
+}
+
+func TestAssignIds(t *testing.T) {
+	extract, extract2 := newNopTask("extract"), newNopTask("extract")
+	pipelineExtract, pipelineLoad := newNopTask("extract"), newNopTask("load")
+	mapped := NewTaskMapped("mapped", executor.NewGolangExecuter(nil), MappedConfig{Upstream: "extract"})
+	rootMapped := NewTaskMapped("root_mapped", executor.NewGolangExecuter(nil), MappedConfig{Upstream: "extract"})
+	branch := NewTaskBranch("branch", executor.NewGolangExecuter(nil), NewTaskBranchPipeline(map[string][]Execution{
+		"a": {pipelineExtract, pipelineLoad, mapped},
+	}))
+
+	if err := AssignIds([]Execution{extract, extract2, branch, rootMapped}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[Execution]string{
+		extract:         "extract",
+		extract2:        "extract#2",
+		branch:          "branch",
+		pipelineExtract: "branch/a/extract",
+		pipelineLoad:    "branch/a/load",
+		mapped:          "branch/a/mapped",
+	}
+	for execution, id := range expected {
+		if execution.GetId() != id {
+			t.Errorf("expected id %s, got %s", id, execution.GetId())
+		}
+	}
+	if upstream := mapped.(*TaskMapped).GetUpstream(); upstream != "branch/a/extract" {
+		t.Errorf("expected upstream in same pipeline, got %s", upstream)
+	}
+	if upstream := rootMapped.(*TaskMapped).GetUpstream(); upstream != "extract" {
+		t.Errorf("expected upstream on root, got %s", upstream)
+	}
+}
+
+func TestAssignIdsError(t *testing.T) {
+	a := newNopTask("a")
+	cases := []struct {
+		name  string
+		tasks []Execution
+		err   string
+	}{
+		{name: "added twice", tasks: []Execution{a, a}, err: "was added in job more than once"},
+		{name: "duplicated id", tasks: []Execution{newNopTask("b"), newNopTask("b"), newNopTask("b#2")}, err: "task id b#2 is duplicated"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := AssignIds(tc.tasks); err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+)
+
+type MappedConfig struct {
+	Upstream  string // name of upstream task which return list, it is task in same pipeline when mapped task is in branch
+	MaxActive int    // max instance which run concurrently, 0 is no limit
+}
+
+/*
+task which is expanded on runtime into instance per item of upstream value,
+instance is named by index suffix (name[0]) and value of task is list of value of every instance by order of item
+*/
+type TaskMapped struct {
+	taskbase   `json:",inline"`
+	fn         executor.Execution
+	config     MappedConfig
+	upstreamId string // id of upstream task, it is assigned when job was registered
+}
+
+func NewTaskMapped(name string, execution executor.Execution, config MappedConfig) Execution {
+	return &TaskMapped{
+		taskbase: taskbase{
+			taskType:    constants.TASK_TYPE_MAPPED_TASK,
+			name:        name,
+			triggerRule: constants.TRIGGER_RULE_ALL_SUCCESS,
+		},
+		fn:     execution,
+		config: config,
+	}
+}
+
+func (s TaskMapped) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		TaskType      string      `json:"type"`
+		Name          string      `json:"name"`
+		Id            string      `json:"id"`
+		ExecutionName string      `json:"execution_name"`
+		TriggerRule   string      `json:"trigger_rule"`
+		Group         string      `json:"group,omitempty"`
+		Options       TaskOptions `json:"options"`
+		Upstream      string      `json:"upstream"`
+		MaxActive     int         `json:"max_active"`
+	}
+	sh := ptr{
+		TaskType:      string(s.taskbase.taskType),
+		Name:          s.taskbase.name,
+		Id:            s.taskbase.GetId(),
+		ExecutionName: s.fn.GetName(),
+		TriggerRule:   string(s.taskbase.GetTriggerRule()),
+		Group:         s.taskbase.group,
+		Options:       s.taskbase.options,
+		Upstream:      s.config.Upstream,
+		MaxActive:     s.config.MaxActive,
+	}
+	return json.Marshal(sh)
+}
+
+func (t TaskMapped) GetType() constants.TaskType {
+	return t.taskType
+}
+
+func (t TaskMapped) GetName() string {
+	return t.name
+}
+
+func (t TaskMapped) GetExecutionName() string {
+	return t.fn.GetName()
+}
+
+/* id of upstream task, upstream in same pipeline of branch is resolved to id of that task */
+func (t TaskMapped) GetUpstream() string {
+	if t.upstreamId == "" {
+		return t.config.Upstream
+	}
+	return t.upstreamId
+}
+
+func (t TaskMapped) GetMaxActive() int {
+	return t.config.MaxActive
+}
+
+/* value of upstream must be slice or array, nil is empty list */
+func (t TaskMapped) GetItems(value interface{}) ([]interface{}, error) {
+	if value == nil {
+		return []interface{}{}, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("value of task %s must be list for mapped task %s", t.config.Upstream, t.name)
+	}
+	var items = make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		items = append(items, rv.Index(i).Interface())
+	}
+	return items, nil
+}
+
+/* instance of every item, item and index are passed to executor by context */
+func (t TaskMapped) Expand(items []interface{}) []Execution {
+	var instances = make([]Execution, 0, len(items))
+	for index, item := range items {
+		instance := NewTask(fmt.Sprintf("%s[%d]", t.name, index), mappedExecution{
+			fn:    t.fn,
+			index: index,
+			item:  item,
+		}).(*Task)
+		instance.group = t.group
+		instance.options = t.options
+		instance.id = fmt.Sprintf("%s[%d]", t.GetId(), index)
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+/* run every instance by order, job runner run instance concurrently by max active */
+func (t TaskMapped) Call(ctx context.Context) (interface{}, error) {
+	type taskValueReader interface {
+		GetTaskValue(taskId string) (data interface{}, ok bool)
+	}
+	runner, ok := ctx.Value(constants.JOB_RUNNER_INSTANCE_KEY).(taskValueReader)
+	if !ok {
+		return nil, fmt.Errorf("job runner was not found on mapped task %s", t.name)
+	}
+	value, _ := runner.GetTaskValue(t.GetUpstream())
+	items, err := t.GetItems(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var values = make([]interface{}, 0, len(items))
+	for _, instance := range t.Expand(items) {
+		val, err := instance.Call(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+type mappedExecution struct {
+	fn    executor.Execution
+	index int
+	item  interface{}
+}
+
+func (m mappedExecution) GetName() string {
+	return m.fn.GetName()
+}
+
+func (m mappedExecution) Execute(ctx context.Context) (interface{}, error) {
+	ctx = context.WithValue(ctx, constants.MAP_INDEX_KEY, m.index)
+	ctx = context.WithValue(ctx, constants.MAP_ITEM_KEY, m.item)
+	return m.fn.Execute(ctx)
+}
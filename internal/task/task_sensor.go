@@ -0,0 +1,137 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+)
+
+const (
+	default_sensor_poke_interval = 30 * time.Second
+)
+
+type SensorConfig struct {
+	Mode         constants.SensorMode // default is poke
+	PokeInterval time.Duration        // default is 30 second
+	Timeout      time.Duration        // deadline of whole sensor, 0 is no deadline. TaskTimeout of scheduler is deadline of each poke
+}
+
+/* task which wait until condition of sensor was met */
+type TaskSensor struct {
+	taskbase `json:",inline"`
+	sensor   executor.Sensor
+	config   SensorConfig
+}
+
+func NewTaskSensor(name string, sensor executor.Sensor, config SensorConfig) Execution {
+	if config.Mode == "" {
+		config.Mode = constants.SENSOR_MODE_POKE
+	}
+	if config.PokeInterval <= 0 {
+		config.PokeInterval = default_sensor_poke_interval
+	}
+	return &TaskSensor{
+		taskbase: taskbase{
+			taskType:    constants.TASK_TYPE_SENSOR_TASK,
+			name:        name,
+			triggerRule: constants.TRIGGER_RULE_ALL_SUCCESS,
+		},
+		sensor: sensor,
+		config: config,
+	}
+}
+
+func (s TaskSensor) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		TaskType      string      `json:"type"`
+		Name          string      `json:"name"`
+		Id            string      `json:"id"`
+		ExecutionName string      `json:"execution_name"`
+		TriggerRule   string      `json:"trigger_rule"`
+		Group         string      `json:"group,omitempty"`
+		Options       TaskOptions `json:"options"`
+		Mode          string      `json:"mode"`
+		PokeInterval  string      `json:"poke_interval"`
+		Timeout       string      `json:"timeout"`
+	}
+	sh := ptr{
+		TaskType:      string(s.taskbase.taskType),
+		Name:          s.taskbase.name,
+		Id:            s.taskbase.GetId(),
+		ExecutionName: s.sensor.GetName(),
+		TriggerRule:   string(s.taskbase.GetTriggerRule()),
+		Group:         s.taskbase.group,
+		Options:       s.taskbase.options,
+		Mode:          string(s.config.Mode),
+		PokeInterval:  s.config.PokeInterval.String(),
+		Timeout:       s.config.Timeout.String(),
+	}
+	return json.Marshal(sh)
+}
+
+func (t TaskSensor) GetType() constants.TaskType {
+	return t.taskType
+}
+
+func (t TaskSensor) GetName() string {
+	return t.name
+}
+
+func (t TaskSensor) GetExecutionName() string {
+	return t.sensor.GetName()
+}
+
+func (t TaskSensor) GetMode() constants.SensorMode {
+	return t.config.Mode
+}
+
+func (t TaskSensor) GetPokeInterval() time.Duration {
+	return t.config.PokeInterval
+}
+
+func (t TaskSensor) GetTimeout() time.Duration {
+	return t.config.Timeout
+}
+
+func (t TaskSensor) Poke(ctx context.Context) (interface{}, bool, error) {
+	return t.sensor.Poke(ctx)
+}
+
+/* error when sensor was not met within timeout, it is wrapped context.DeadlineExceeded */
+func (t TaskSensor) NewTimeoutError() error {
+	return fmt.Errorf("sensor %s was not met within %s: %w", t.name, t.config.Timeout.String(), context.DeadlineExceeded)
+}
+
+/* poke until condition was met, job runner call Poke directly for release slot of scheduler on reschedule mode */
+func (t TaskSensor) Call(ctx context.Context) (interface{}, error) {
+	if t.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.config.Timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(t.config.PokeInterval)
+	defer ticker.Stop()
+	for {
+		value, ok, err := t.Poke(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return value, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, t.NewTimeoutError()
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/executor"
+)
+
+/* task which executor return bool, every downstream task is skipped when it return false and job is still success */
+type TaskShortCircuit struct {
+	taskbase `json:",inline"`
+	fn       executor.Execution
+}
+
+func NewTaskShortCircuit(name string, execution executor.Execution) Execution {
+	return &TaskShortCircuit{
+		taskbase: taskbase{
+			taskType:    constants.TASK_TYPE_SHORT_CIRCUIT_TASK,
+			name:        name,
+			triggerRule: constants.TRIGGER_RULE_ALL_SUCCESS,
+		},
+		fn: execution,
+	}
+}
+
+func (s TaskShortCircuit) MarshalJSON() ([]byte, error) {
+	type ptr struct {
+		TaskType      string      `json:"type"`
+		Name          string      `json:"name"`
+		Id            string      `json:"id"`
+		ExecutionName string      `json:"execution_name"`
+		TriggerRule   string      `json:"trigger_rule"`
+		Group         string      `json:"group,omitempty"`
+		Options       TaskOptions `json:"options"`
+	}
+	sh := ptr{
+		TaskType:      string(s.taskbase.taskType),
+		Name:          s.taskbase.name,
+		Id:            s.taskbase.GetId(),
+		ExecutionName: s.fn.GetName(),
+		TriggerRule:   string(s.taskbase.GetTriggerRule()),
+		Group:         s.taskbase.group,
+		Options:       s.taskbase.options,
+	}
+	return json.Marshal(sh)
+}
+
+func (t TaskShortCircuit) GetType() constants.TaskType {
+	return t.taskType
+}
+
+func (t TaskShortCircuit) GetName() string {
+	return t.name
+}
+
+func (t TaskShortCircuit) GetExecutionName() string {
+	return t.fn.GetName()
+}
+
+/* value of task is result of condition */
+func (t TaskShortCircuit) Call(ctx context.Context) (interface{}, error) {
+	value, err := t.fn.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	isContinue, ok := value.(bool)
+	if !ok {
+		return nil, errors.New("TaskShortCircuit must be return value on type bool")
+	}
+	return isContinue, nil
+}
+
+/* downstream is skipped when value of task is false */
+func (t TaskShortCircuit) IsShortCircuited(value interface{}) bool {
+	isContinue, ok := value.(bool)
+	return ok && !isContinue
+}
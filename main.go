@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/BlackMocca/sqlx"
 	"github.com/Blackmocca/go-lightweight-scheduler/dag"
 	_ "github.com/Blackmocca/go-lightweight-scheduler/dag"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/connection"
@@ -38,7 +40,7 @@ func getWebInstance(adapterConnection connection.DatabaseAdapterConnection) (*ec
 	e.Use(middL.InputForm)
 
 	router := route.NewRoute(e, middL)
-	router.RegisterHealthcheck()
+	router.RegisterHealthcheck(dag.ELECTOR)
 
 	schedulHandler := _schedule_handler.NewScheduleHandler(adapterConnection.GetRepository())
 	router.RegisterSchedule(schedulHandler, _schedule_validator.NewValidation())
@@ -55,6 +57,14 @@ func main() {
 	}
 	defer adapterConnection.Close(ctx)
 
+	if client, ok := adapterConnection.GetClient().(*sqlx.DB); ok {
+		connection.RegisterSqlConnection(connection.SQL_CONNECTION_DEFAULT, client)
+	}
+	if err := connection.RegisterSqlConnectionFromEnv(ctx); err != nil {
+		panic(err)
+	}
+	defer connection.CloseSqlConnections()
+
 	if constants.ENV_MIGRATE_TABLE {
 		if err := connection.MigrateUp(adapterConnection); err != nil {
 			panic(err)
@@ -65,11 +75,17 @@ func main() {
 
 	go func() {
 		port := fmt.Sprintf(":%s", constants.ENV_APP_PORT)
-		e.Logger.Fatal(e.Start(port))
+		if err := e.Start(port); err != nil && err != http.ErrServerClosed {
+			e.Logger.Fatal(err)
+		}
 	}()
 
 	stop := make(chan bool)
-	go dag.StartAllDag(stop, adapterConnection)
+	stopped := make(chan struct{})
+	go func() {
+		dag.StartAllDag(stop, adapterConnection)
+		close(stopped)
+	}()
 
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt, os.Kill, syscall.SIGTERM)
@@ -78,9 +94,13 @@ func main() {
 	<-signalCh
 	fmt.Println("cancel application")
 
-	e.Shutdown(ctx)
-	fmt.Println("shutdown web service")
-
+	/* api reject new trigger during scheduler drain running job */
 	stop <- true
+	<-stopped
 	fmt.Println("shutdown scheduler")
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, constants.ENV_SHUTDOWN_GRACE_PERIOD)
+	defer cancel()
+	e.Shutdown(shutdownCtx)
+	fmt.Println("shutdown web service")
 }
@@ -3,6 +3,8 @@ package route
 import (
 	"net/http"
 
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/scheduler"
 	"github.com/Blackmocca/go-lightweight-scheduler/middleware"
 	"github.com/Blackmocca/go-lightweight-scheduler/service/v1/schedule"
 	_schedule_validator "github.com/Blackmocca/go-lightweight-scheduler/service/v1/schedule/validator"
@@ -22,18 +24,29 @@ func NewRoute(e *echo.Echo, middl middleware.RestAPIMiddleware) *Route {
 	return &Route{e: e, middl: middl, auth: auth}
 }
 
-func (r Route) RegisterHealthcheck() {
+/* elector is nil when leader election is disabled */
+func (r Route) RegisterHealthcheck(elector *scheduler.LeaderElector) {
 	r.e.GET("/healthcheck", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]interface{}{
-			"status": "ok",
-		})
+		var resp = map[string]interface{}{
+			"status":  "ok",
+			"node_id": constants.ENV_NODE_ID,
+		}
+		if elector != nil {
+			resp["leader_election"] = elector
+		}
+		return c.JSON(http.StatusOK, resp)
 	})
 }
 
 func (r Route) RegisterSchedule(handler schedule.HttpHandler, validation _schedule_validator.Validation) {
 	r.auth.GET("/v1/schedulers", handler.GetListSchedule)
 	r.auth.GET("/v1/scheduler/:name", handler.GetOneSchedule)
+	r.auth.POST("/v1/admin/schedulers/reload", handler.ReloadSchedule)
+	r.auth.PUT("/v1/scheduler/:name/pause", handler.PauseSchedule, validation.ValidatePauseScheduler)
+	r.auth.PUT("/v1/scheduler/:name/resume", handler.ResumeSchedule)
+	r.auth.POST("/v1/scheduler/:name/backfill", handler.Backfill, validation.ValidateBackfill)
 	r.auth.GET("/v1/job/:job_id", handler.GetOneJobById)
+	r.auth.POST("/v1/job/:job_id/rerun", handler.RerunJob, validation.ValidateRerunJob)
 	r.auth.POST("/v1/scheduler/triggers", handler.Trigger, validation.ValidateTrigger)
 
 	r.auth.GET("/v1/jobs", handler.GetListJob)
@@ -5,8 +5,13 @@ import "github.com/labstack/echo/v4"
 type HttpHandler interface {
 	GetListSchedule(echo.Context) error
 	GetOneSchedule(echo.Context) error
+	ReloadSchedule(echo.Context) error
+	PauseSchedule(echo.Context) error
+	ResumeSchedule(echo.Context) error
+	Backfill(echo.Context) error
 	Trigger(echo.Context) error
 	GetOneJobById(echo.Context) error
+	RerunJob(echo.Context) error
 	GetListJob(echo.Context) error
 	GetListJobTask(c echo.Context) error
 	GetListJobFuture(c echo.Context) error
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,32 +27,82 @@ func NewScheduleHandler(repository schedule.Repository) schedule.HttpHandler {
 }
 
 func (sh scheduleHandler) getOneSchedule(name string) *scheduler.SchedulerInstance {
-	var schedule *scheduler.SchedulerInstance
+	return dag.SCHEDULERS.Get(name)
+}
 
-	for _, item := range dag.SCHEDULERS {
-		if name == item.GetName() {
-			schedule = item
-			break
+func (sh scheduleHandler) GetListSchedule(c echo.Context) error {
+	var ctx = c.Request().Context()
+	var schedulers = dag.SCHEDULERS.List()
+	for _, schedule := range schedulers {
+		if err := schedule.LoadState(ctx); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
 
-	return schedule
+	resp := map[string]interface{}{
+		"schedulers": schedulers,
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
-func (sh scheduleHandler) GetListSchedule(c echo.Context) error {
+func (sh scheduleHandler) ReloadSchedule(c echo.Context) error {
+	result := dag.ReloadDefinition()
+
 	resp := map[string]interface{}{
-		"schedulers": dag.SCHEDULERS,
+		"result": result,
 	}
 	return c.JSON(http.StatusOK, resp)
 }
 
 func (sh scheduleHandler) GetOneSchedule(c echo.Context) error {
+	var ctx = c.Request().Context()
 	var name = c.Param("name")
 	var schedule = sh.getOneSchedule(name)
 
 	if schedule == nil {
 		return echo.NewHTTPError(http.StatusNoContent)
 	}
+	if err := schedule.LoadState(ctx); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := map[string]interface{}{
+		"scheduler": schedule,
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (sh scheduleHandler) PauseSchedule(c echo.Context) error {
+	var ctx = c.Request().Context()
+	var params = c.Get("params").(map[string]interface{})
+	var name = c.Param("name")
+	var rejectTrigger = cast.ToBool(params["reject_trigger"])
+	var schedule = sh.getOneSchedule(name)
+	if schedule == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("scheduler name '%s' not found", name))
+	}
+
+	if err := schedule.Pause(ctx, rejectTrigger); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := map[string]interface{}{
+		"scheduler": schedule,
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (sh scheduleHandler) ResumeSchedule(c echo.Context) error {
+	var ctx = c.Request().Context()
+	var name = c.Param("name")
+	var schedule = sh.getOneSchedule(name)
+	if schedule == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("scheduler name '%s' not found", name))
+	}
+
+	if err := schedule.Resume(ctx); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
 	resp := map[string]interface{}{
 		"scheduler": schedule,
@@ -59,6 +110,35 @@ func (sh scheduleHandler) GetOneSchedule(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+func (sh scheduleHandler) Backfill(c echo.Context) error {
+	var ctx = c.Request().Context()
+	var params = c.Get("params").(map[string]interface{})
+	var name = c.Param("name")
+	var startDatetime, _ = time.Parse(time.RFC3339, cast.ToString(params["start_datetime"]))
+	var endDatetime, _ = time.Parse(time.RFC3339, cast.ToString(params["end_datetime"]))
+	var schedule = sh.getOneSchedule(name)
+	if dag.SCHEDULERS.IsShutdown() {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "scheduler is shutting down")
+	}
+	if schedule == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("scheduler name '%s' not found", name))
+	}
+
+	ticks, err := schedule.GetCronTicks(startDatetime, endDatetime)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	triggers, err := schedule.Backfill(ctx, ticks)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := map[string]interface{}{
+		"triggers": triggers,
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
 func (sh scheduleHandler) Trigger(c echo.Context) error {
 	var ctx = c.Request().Context()
 	var params = c.Get("params").(map[string]interface{})
@@ -66,9 +146,18 @@ func (sh scheduleHandler) Trigger(c echo.Context) error {
 	var executeDatetime, _ = time.Parse(time.RFC3339, cast.ToString(params["execute_datetime"]))
 	var config = params["config"]
 	var schedule = sh.getOneSchedule(name)
+	if dag.SCHEDULERS.IsShutdown() {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "scheduler is shutting down")
+	}
 	if schedule == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("scheduler name '%s' not found", name))
 	}
+	if err := schedule.LoadState(ctx); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if state := schedule.GetState(); state.IsPaused && state.RejectTrigger {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("scheduler name '%s' was paused", name))
+	}
 
 	uid, _ := uuid.NewV4()
 	trigger := &models.Trigger{
@@ -96,6 +185,32 @@ func (sh scheduleHandler) Trigger(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+/* group job task by path of task group (group.subgroup), task which was not in any group is on root */
+func buildJobTaskTree(jobtasks []*models.JobTask) *models.JobTaskGroup {
+	var root = &models.JobTaskGroup{Tasks: make([]*models.JobTask, 0), Groups: make([]*models.JobTaskGroup, 0)}
+	var groups = map[string]*models.JobTaskGroup{"": root}
+	var getGroup func(path string) *models.JobTaskGroup
+	getGroup = func(path string) *models.JobTaskGroup {
+		if group, ok := groups[path]; ok {
+			return group
+		}
+		var parent, name = "", path
+		if index := strings.LastIndex(path, "."); index >= 0 {
+			parent, name = path[:index], path[index+1:]
+		}
+		parentGroup := getGroup(parent)
+		group := &models.JobTaskGroup{Name: name, Tasks: make([]*models.JobTask, 0), Groups: make([]*models.JobTaskGroup, 0)}
+		groups[path] = group
+		parentGroup.Groups = append(parentGroup.Groups, group)
+		return group
+	}
+	for _, jobtask := range jobtasks {
+		group := getGroup(jobtask.TaskGroup)
+		group.Tasks = append(group.Tasks, jobtask)
+	}
+	return root
+}
+
 func (sh scheduleHandler) GetOneJobById(c echo.Context) error {
 	var ctx = c.Request().Context()
 	var jobId = c.Param("job_id")
@@ -128,6 +243,14 @@ func (sh scheduleHandler) GetOneJobById(c echo.Context) error {
 	}
 	if len(jobtasks) > 0 {
 		job.JobRunningTasks = jobtasks
+		job.JobTaskTree = buildJobTaskTree(jobtasks)
+	}
+
+	/* task value and parameter are saved by serializer of scheduler */
+	schedulerInstance := sh.getOneSchedule(job.SchedulerName)
+	job.Parameter = scheduler.DeserializeValue(schedulerInstance, job.ParameterString)
+	for _, jobtask := range jobtasks {
+		jobtask.Value = scheduler.DeserializeValue(schedulerInstance, jobtask.TaskValue)
 	}
 
 	resp := map[string]interface{}{
@@ -136,6 +259,64 @@ func (sh scheduleHandler) GetOneJobById(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+func (sh scheduleHandler) RerunJob(c echo.Context) error {
+	var ctx = c.Request().Context()
+	var params = c.Get("params").(map[string]interface{})
+	var jobId = c.Param("job_id")
+	var mode = constants.RerunMode(cast.ToString(params["mode"]))
+	if dag.SCHEDULERS.IsShutdown() {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "scheduler is shutting down")
+	}
+
+	job, err := sh.repository.GetOneJob(ctx, jobId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if job == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("job id '%s' not found", jobId))
+	}
+	switch job.Status {
+	case constants.JOB_STATUS_WAITING, constants.JOB_STATUS_RUNNING:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("job id '%s' is %s", jobId, job.Status))
+	}
+
+	var schedule = sh.getOneSchedule(job.SchedulerName)
+	if schedule == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("scheduler name '%s' not found", job.SchedulerName))
+	}
+	if err := schedule.LoadState(ctx); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if state := schedule.GetState(); state.IsPaused && state.RejectTrigger {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("scheduler name '%s' was paused", job.SchedulerName))
+	}
+
+	jobtasks, err := sh.repository.GetOneJobTaskByJobId(ctx, jobId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	var triggerConfig = new(sync.Map)
+	trigger, err := sh.repository.GetOneTriggerByJobId(ctx, jobId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if trigger != nil {
+		triggerConfig = trigger.GetConfigMutex()
+	}
+
+	if err := schedule.Rerun(ctx, job, jobtasks, triggerConfig, mode); err != nil {
+		if err.Error() == constants.ERROR_JOB_CLAIMED {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("job id '%s' was rerun by another request", jobId))
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := map[string]interface{}{
+		"job_id": job.JobId,
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
 func (sh scheduleHandler) getArgs(c echo.Context) *sync.Map {
 	var args = new(sync.Map)
 	var searchWord = c.QueryParam("search_word")
@@ -3,7 +3,9 @@ package schedule
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/Blackmocca/go-lightweight-scheduler/internal/constants"
 	"github.com/Blackmocca/go-lightweight-scheduler/internal/models"
 	"github.com/gofrs/uuid"
 )
@@ -11,16 +13,42 @@ import (
 type Repository interface {
 	GetOneTriggerByJobId(ctx context.Context, jobId string) (*models.Trigger, error)
 	GetOneJob(ctx context.Context, jobId string) (*models.Job, error)
+	GetLastestJobByScheduler(ctx context.Context, schedulerName string, since time.Time, statuses ...constants.JobStatus) (*models.Job, error)
 	GetOneJobTaskByJobId(ctx context.Context, jobId string) ([]*models.JobTask, error)
-	GetTriggerTimer(ctx context.Context, schedulerName string) ([]*models.Trigger, error)
+	GetLastestSuccessJobTask(ctx context.Context, schedulerName string, jobId string, taskId string) (*models.JobTask, error)
+	ClaimDueTriggers(ctx context.Context, nodeId string, schedulerNames []string, executeDatetime time.Time, limit int) ([]*models.Trigger, error)
+	GetLastTriggerSchedule(ctx context.Context, schedulerName string) (*models.Trigger, error)
 	GetJobs(ctx context.Context, args *sync.Map, page int, perPage int) ([]*models.Job, int, error)
 	GetJobTasks(ctx context.Context, args *sync.Map, page int, perPage int) ([]*models.JobTask, int, error)
 	GetFutureJob(ctx context.Context, args *sync.Map, page int, perPage int) ([]*models.Trigger, int, error)
 	ExecuteFutureJob(ctx context.Context, trigger *models.Trigger) (*models.Trigger, error)
 	UpsertTrigger(ctx context.Context, trigger *models.Trigger) error
-	CreateTriggerByJobScheduler(ctx context.Context, trigger *models.Trigger) error
+	CreateTriggerByJobScheduler(ctx context.Context, trigger *models.Trigger, lease *models.LeaderLease) error
 	UpsertJob(ctx context.Context, job *models.Job) error
 	UpsertJobTask(ctx context.Context, jobTask *models.JobTask) error
 	UnActivatedTrigger(ctx context.Context, schedulerName string, configKey string, configValue interface{}) error
 	UnActivatedTriggerByJobId(ctx context.Context, jobId *uuid.UUID) error
+	GetSchedulerState(ctx context.Context, schedulerName string) (*models.SchedulerState, error)
+	UpsertSchedulerState(ctx context.Context, state *models.SchedulerState) error
+	EnqueueTask(ctx context.Context, item *models.TaskQueue) error
+	ClaimTasks(ctx context.Context, nodeId string, schedulerNames []string, limit int) ([]*models.TaskQueue, error)
+	HeartbeatTasks(ctx context.Context, nodeId string, ids []int64) ([]int64, error)
+	FinishTask(ctx context.Context, item *models.TaskQueue) error
+	CancelTask(ctx context.Context, id int64) error
+	GetTaskQueue(ctx context.Context, id int64) (*models.TaskQueue, error)
+	RequeueStaleTasks(ctx context.Context, timeout time.Duration) (int64, error)
+	AcquireLease(ctx context.Context, name string, nodeId string, ttl time.Duration) (*models.LeaderLease, error)
+	GetLease(ctx context.Context, name string) (*models.LeaderLease, error)
+	CheckLease(ctx context.Context, name string, nodeId string, fencingToken int64) (bool, error)
+	ReleaseLease(ctx context.Context, name string, nodeId string) error
+	HeartbeatJobs(ctx context.Context, nodeId string, jobIds []string) error
+	ClaimOrphanJobs(ctx context.Context, nodeId string, timeout time.Duration) ([]*models.Job, error)
+	ClaimNodeJobs(ctx context.Context, nodeId string, before time.Time) ([]*models.Job, error)
+	ClaimRerunJob(ctx context.Context, jobId string, nodeId string, status constants.JobStatus) (bool, error)
+	FailRunningJobTasks(ctx context.Context, jobId string, exception string) error
+	CancelTasksByJobId(ctx context.Context, jobId string) error
+	ReleaseTrigger(ctx context.Context, jobId string) error
+	RequeueTasks(ctx context.Context, nodeId string, ids []int64) error
+	HeartbeatTriggers(ctx context.Context, nodeId string, jobIds []string) error
+	ReleaseStaleTriggers(ctx context.Context, timeout time.Duration) (int64, error)
 }
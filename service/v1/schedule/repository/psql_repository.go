@@ -38,17 +38,61 @@ func (p psqlRepository) setTrigger(ptr *models.Trigger) {
 	}
 }
 
-func (p psqlRepository) GetTriggerTimer(ctx context.Context, schedulerName string) ([]*models.Trigger, error) {
+/* claim due trigger of scheduler by order of execute datetime, trigger which was claimed by another node is skipped */
+func (p psqlRepository) ClaimDueTriggers(ctx context.Context, nodeId string, schedulerNames []string, executeDatetime time.Time, limit int) ([]*models.Trigger, error) {
 	var ptrs = []*models.Trigger{}
+	if len(schedulerNames) == 0 || limit <= 0 {
+		return ptrs, nil
+	}
+	var vals = []interface{}{nodeId, time.Now(), executeDatetime.Format(constants.TIME_FORMAT_RFC339)}
+	var binds = make([]string, 0, len(schedulerNames))
+	for _, schedulerName := range schedulerNames {
+		binds = append(binds, "?")
+		vals = append(vals, schedulerName)
+	}
+	vals = append(vals, limit)
 	sql := fmt.Sprintf(`
+		UPDATE triggers
+		SET is_trigger=true, node_id=?, claimed_at=NOW(), updated_at=?
+		WHERE job_id IN (
+			SELECT job_id
+			FROM triggers
+			WHERE execute_datetime <= ? AND is_trigger = false AND is_active = true AND scheduler_name IN (%s)
+			ORDER BY execute_datetime ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING triggers.*
+	`, strings.Join(binds, ", "))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SelectContext(ctx, &ptrs, vals...); err != nil {
+		return nil, err
+	}
+	for index := range ptrs {
+		p.setTrigger(ptrs[index])
+	}
+	return ptrs, nil
+}
+
+func (p psqlRepository) GetLastTriggerSchedule(ctx context.Context, schedulerName string) (*models.Trigger, error) {
+	var ptr = new(models.Trigger)
+	sql := `
 		SELECT 
 			*
 		FROM
 			triggers
 		WHERE 
-			scheduler_name = ? AND "type"::text = ? AND is_trigger = false AND is_active = true
-	`,
-	)
+			scheduler_name = ? AND "type"::text = ?
+		ORDER BY execute_datetime DESC
+		LIMIT 1
+	`
 
 	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
 	stmt, err := p.client.PreparexContext(ctx, sql)
@@ -57,16 +101,15 @@ func (p psqlRepository) GetTriggerTimer(ctx context.Context, schedulerName strin
 	}
 	defer stmt.Close()
 
-	if err := stmt.SelectContext(ctx, &ptrs, schedulerName, constants.TRIGGER_TYPE_EXTERNAL); err != nil {
-		return nil, err
-	}
-	if len(ptrs) > 0 {
-		for index, _ := range ptrs {
-			p.setTrigger(ptrs[index])
+	if err := stmt.GetContext(ctx, ptr, schedulerName, constants.TRIGGER_TYPE_SCHEDULE); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
 		}
+		return nil, err
 	}
+	p.setTrigger(ptr)
 
-	return ptrs, nil
+	return ptr, nil
 }
 
 func (p psqlRepository) UpsertTrigger(ctx context.Context, trigger *models.Trigger) error {
@@ -107,12 +150,34 @@ func (p psqlRepository) UpsertTrigger(ctx context.Context, trigger *models.Trigg
 	return err
 }
 
-func (p psqlRepository) CreateTriggerByJobScheduler(ctx context.Context, trigger *models.Trigger) error {
+/*
+lease of leader is locked in same transaction with insert when lease is given,
+leader which was replaced is rejected and lease is not taken over until trigger was committed
+*/
+func (p psqlRepository) CreateTriggerByJobScheduler(ctx context.Context, trigger *models.Trigger, lease *models.LeaderLease) error {
 	var tx, err = p.client.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
 	if err != nil {
 		return err
 	}
 
+	if lease != nil {
+		leaseSql := `
+			SELECT fencing_token
+			FROM leader_leases
+			WHERE name = ? AND node_id = ? AND fencing_token = ? AND expired_at > NOW()
+			FOR SHARE
+		`
+		leaseSql = sqlx.Rebind(sqlx.DOLLAR, leaseSql)
+		var fencingToken int64
+		if err := tx.GetContext(ctx, &fencingToken, leaseSql, lease.Name, lease.NodeId, lease.FencingToken); err != nil {
+			tx.Rollback()
+			if err.Error() == "sql: no rows in result set" {
+				return errors.New(constants.ERROR_LEASE_LOST)
+			}
+			return err
+		}
+	}
+
 	sql := fmt.Sprintf(`
 			INSERT INTO "triggers" ("scheduler_name", "execute_datetime", "job_id", "config", "type", "is_trigger", "is_active", "created_at", "updated_at")
 			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
@@ -220,13 +285,17 @@ func (p psqlRepository) ExecuteFutureJob(ctx context.Context, trigger *models.Tr
 
 func (p psqlRepository) UpsertJob(ctx context.Context, job *models.Job) error {
 	sql := `
-		INSERT INTO "jobs" ("scheduler_name", "job_id", "status", "start_datetime", "end_datetime", "created_at", "updated_at")
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO "jobs" ("scheduler_name", "job_id", "status", "start_datetime", "end_datetime", "parameter", "node_id", "heartbeat_at", "exception", "created_at", "updated_at")
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), ?, ?, ?)
 		ON CONFLICT (scheduler_name, job_id)
 		DO UPDATE SET
 			status=?,
 			start_datetime=?,
 			end_datetime=?,
+			parameter=?,
+			node_id=?,
+			heartbeat_at=NOW(),
+			exception=?,
 			created_at=?,
 			updated_at=?
 	`
@@ -245,12 +314,18 @@ func (p psqlRepository) UpsertJob(ctx context.Context, job *models.Job) error {
 		string(job.Status),
 		job.StartDateTime,
 		job.EndDatetime,
+		job.ParameterString,
+		job.NodeId,
+		job.Exception,
 		job.CreatedAt,
 		job.UpdatedAt,
 		/* update */
 		job.Status,
 		job.StartDateTime,
 		job.EndDatetime,
+		job.ParameterString,
+		job.NodeId,
+		job.Exception,
 		job.CreatedAt,
 		job.UpdatedAt,
 	)
@@ -260,17 +335,21 @@ func (p psqlRepository) UpsertJob(ctx context.Context, job *models.Job) error {
 
 func (p psqlRepository) UpsertJobTask(ctx context.Context, jobTask *models.JobTask) error {
 	sql := `
-	INSERT INTO "job_tasks" ("scheduler_name", "job_id", "task_status", "task_name", "task_type", "execution_name", "start_datetime", "end_datetime", "exception", "stacktrace", "created_at", "updated_at")
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	ON CONFLICT (job_id, task_name)
+	INSERT INTO "job_tasks" ("scheduler_name", "job_id", "task_status", "task_id", "task_name", "task_type", "execution_name", "attempt", "start_datetime", "end_datetime", "exception", "stacktrace", "task_value", "task_group", "sla_missed", "created_at", "updated_at")
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (job_id, task_id, attempt)
 	DO UPDATE SET
 		task_status=?,
+		task_name=?,
 		task_type=?,
 		execution_name=?,
 		start_datetime=?,
 		end_datetime=?,
 		exception=?,
 		stacktrace=?,
+		task_value=?,
+		task_group=?,
+		sla_missed=?,
 		created_at=?,
 		updated_at=?
 	`
@@ -287,23 +366,32 @@ func (p psqlRepository) UpsertJobTask(ctx context.Context, jobTask *models.JobTa
 		jobTask.SchedulerName,
 		jobTask.JobId,
 		jobTask.Status,
+		jobTask.TaskId,
 		jobTask.TaskName,
 		jobTask.TaskType,
 		jobTask.ExecutionName,
+		jobTask.Attempt,
 		jobTask.StartDateTime,
 		jobTask.EndDatetime,
 		jobTask.TaskException,
 		jobTask.StackTrace,
+		jobTask.TaskValue,
+		jobTask.TaskGroup,
+		jobTask.SlaMissed,
 		jobTask.CreatedAt,
 		jobTask.UpdatedAt,
 		/* update */
 		jobTask.Status,
+		jobTask.TaskName,
 		jobTask.TaskType,
 		jobTask.ExecutionName,
 		jobTask.StartDateTime,
 		jobTask.EndDatetime,
 		jobTask.TaskException,
 		jobTask.StackTrace,
+		jobTask.TaskValue,
+		jobTask.TaskGroup,
+		jobTask.SlaMissed,
 		jobTask.CreatedAt,
 		jobTask.UpdatedAt,
 	)
@@ -368,6 +456,48 @@ func (p psqlRepository) GetOneJob(ctx context.Context, jobId string) (*models.Jo
 	return ptr, nil
 }
 
+/* return lastest job of scheduler which was started since datetime, empty statuses is any status */
+func (p psqlRepository) GetLastestJobByScheduler(ctx context.Context, schedulerName string, since time.Time, statuses ...constants.JobStatus) (*models.Job, error) {
+	var ptr = new(models.Job)
+	var conds = []string{"scheduler_name = ?", "start_datetime >= ?"}
+	var vals = []interface{}{schedulerName, since}
+	if len(statuses) > 0 {
+		var binds = make([]string, 0, len(statuses))
+		for _, status := range statuses {
+			binds = append(binds, "?")
+			vals = append(vals, status)
+		}
+		conds = append(conds, fmt.Sprintf("status::text IN (%s)", strings.Join(binds, ", ")))
+	}
+	sql := fmt.Sprintf(`
+		SELECT 
+			*
+		FROM
+			jobs
+		WHERE
+			%s
+		ORDER BY
+			start_datetime DESC
+		LIMIT 1
+	`, strings.Join(conds, " AND "))
+
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, ptr, vals...); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ptr, nil
+}
+
 func (p psqlRepository) GetOneJobTaskByJobId(ctx context.Context, jobId string) ([]*models.JobTask, error) {
 	var ptrs = []*models.JobTask{}
 	sql := `
@@ -377,6 +507,8 @@ func (p psqlRepository) GetOneJobTaskByJobId(ctx context.Context, jobId string)
 			job_tasks
 		WHERE
 			job_id = ?
+		ORDER BY
+			id ASC
 	`
 
 	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
@@ -396,6 +528,48 @@ func (p psqlRepository) GetOneJobTaskByJobId(ctx context.Context, jobId string)
 	return ptrs, nil
 }
 
+/* return lastest task which was success, scheduler name and job id are ignored when it is empty */
+func (p psqlRepository) GetLastestSuccessJobTask(ctx context.Context, schedulerName string, jobId string, taskId string) (*models.JobTask, error) {
+	var ptr = new(models.JobTask)
+	var conds = []string{"task_id = ?", "task_status::text = ?"}
+	var vals = []interface{}{taskId, constants.JOB_STATUS_SUCCESS}
+	if schedulerName != "" {
+		conds = append(conds, "scheduler_name = ?")
+		vals = append(vals, schedulerName)
+	}
+	if jobId != "" {
+		conds = append(conds, "job_id = ?")
+		vals = append(vals, jobId)
+	}
+	sql := fmt.Sprintf(`
+		SELECT 
+			*
+		FROM
+			job_tasks
+		WHERE
+			%s
+		ORDER BY
+			end_datetime DESC, id DESC
+		LIMIT 1
+	`, strings.Join(conds, " AND "))
+
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, ptr, vals...); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ptr, nil
+}
+
 func (p psqlRepository) filterJob(args *sync.Map) ([]string, []interface{}) {
 	var conds = []string{}
 	var vals = []interface{}{}
@@ -729,6 +903,8 @@ func (p psqlRepository) GetJobTasks(ctx context.Context, args *sync.Map, page in
 			job_tasks.start_datetime,
 			job_tasks.end_datetime,
 			job_tasks.exception,
+			job_tasks.attempt,
+			job_tasks.task_id,
 			COUNT(*) OVER() as total_row
 		FROM
 			job_tasks
@@ -782,15 +958,19 @@ func (p psqlRepository) GetJobTasks(ctx context.Context, args *sync.Map, page in
 				}
 			}
 			exception := cast.ToString(rv[9])
-			totalRow = cast.ToInt(rv[10])
+			attempt := cast.ToInt(rv[10])
+			taskId := cast.ToString(rv[11])
+			totalRow = cast.ToInt(rv[12])
 			task := &models.JobTask{
 				Id:            id,
 				SchedulerName: schedulerName,
 				JobId:         jobId,
 				Status:        status,
+				TaskId:        taskId,
 				TaskName:      taskName,
 				TaskType:      taskType,
 				ExecutionName: executeName,
+				Attempt:       attempt,
 				StartDateTime: st,
 				EndDatetime:   en,
 				TaskException: exception,
@@ -839,3 +1019,635 @@ func (p psqlRepository) UnActivatedTriggerByJobId(ctx context.Context, jobId *uu
 	_, err = stmt.ExecContext(ctx, false, time.Now(), jobId)
 	return err
 }
+
+func (p psqlRepository) GetSchedulerState(ctx context.Context, schedulerName string) (*models.SchedulerState, error) {
+	var ptr = new(models.SchedulerState)
+	sql := `
+		SELECT 
+			*
+		FROM
+			scheduler_states
+		WHERE
+			scheduler_name = ?
+	`
+
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, ptr, schedulerName); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ptr, nil
+}
+
+func (p psqlRepository) UpsertSchedulerState(ctx context.Context, state *models.SchedulerState) error {
+	sql := `
+		INSERT INTO "scheduler_states" ("scheduler_name", "is_paused", "reject_trigger", "created_at", "updated_at")
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (scheduler_name)
+		DO UPDATE SET
+			is_paused=?,
+			reject_trigger=?,
+			updated_at=?
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx,
+		/* insert */
+		state.SchedulerName,
+		state.IsPaused,
+		state.RejectTrigger,
+		state.CreatedAt,
+		state.UpdatedAt,
+		/* update */
+		state.IsPaused,
+		state.RejectTrigger,
+		state.UpdatedAt,
+	)
+
+	return err
+}
+
+/* task which was enqueued again by same attempt is reset to queued */
+func (p psqlRepository) EnqueueTask(ctx context.Context, item *models.TaskQueue) error {
+	sql := fmt.Sprintf(`
+		INSERT INTO "task_queues" ("scheduler_name", "job_id", "task_id", "attempt", "status", "payload", "created_at", "updated_at")
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (job_id, task_id, attempt)
+		DO UPDATE SET
+			status='%s',
+			payload=EXCLUDED.payload,
+			result='',
+			parameter='',
+			exception='',
+			stacktrace='',
+			node_id=NULL,
+			heartbeat_at=NULL,
+			updated_at=EXCLUDED.updated_at
+		RETURNING id
+	`, string(constants.QUEUE_STATUS_QUEUED))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	return stmt.QueryRowxContext(ctx,
+		/* insert */
+		item.SchedulerName,
+		item.JobId,
+		item.TaskId,
+		item.Attempt,
+		constants.QUEUE_STATUS_QUEUED,
+		item.Payload,
+		item.CreatedAt,
+		item.UpdatedAt,
+	).Scan(&item.Id)
+}
+
+/* claim queued task of schedulers by order of enqueue, task which was locked by another worker is skipped */
+func (p psqlRepository) ClaimTasks(ctx context.Context, nodeId string, schedulerNames []string, limit int) ([]*models.TaskQueue, error) {
+	var ptrs = make([]*models.TaskQueue, 0)
+	if len(schedulerNames) == 0 || limit <= 0 {
+		return ptrs, nil
+	}
+	var vals = []interface{}{constants.QUEUE_STATUS_RUNNING, nodeId, constants.QUEUE_STATUS_QUEUED}
+	var binds = make([]string, 0, len(schedulerNames))
+	for _, schedulerName := range schedulerNames {
+		binds = append(binds, "?")
+		vals = append(vals, schedulerName)
+	}
+	vals = append(vals, limit)
+	sql := fmt.Sprintf(`
+		UPDATE task_queues
+		SET status=?, node_id=?, heartbeat_at=NOW(), claim_count=claim_count+1, updated_at=NOW()
+		WHERE id IN (
+			SELECT id
+			FROM task_queues
+			WHERE status = ? AND scheduler_name IN (%s)
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING task_queues.*
+	`, strings.Join(binds, ", "))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SelectContext(ctx, &ptrs, vals...); err != nil {
+		return nil, err
+	}
+	return ptrs, nil
+}
+
+/* keep heartbeat of running task, return id of task which is owned by node yet */
+func (p psqlRepository) HeartbeatTasks(ctx context.Context, nodeId string, ids []int64) ([]int64, error) {
+	var owned = make([]int64, 0)
+	if len(ids) == 0 {
+		return owned, nil
+	}
+	var vals = []interface{}{nodeId, constants.QUEUE_STATUS_RUNNING}
+	var binds = make([]string, 0, len(ids))
+	for _, id := range ids {
+		binds = append(binds, "?")
+		vals = append(vals, id)
+	}
+	sql := fmt.Sprintf(`
+		UPDATE task_queues
+		SET heartbeat_at=NOW()
+		WHERE node_id = ? AND status = ? AND id IN (%s)
+		RETURNING id
+	`, strings.Join(binds, ", "))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SelectContext(ctx, &owned, vals...); err != nil {
+		return nil, err
+	}
+	return owned, nil
+}
+
+/* result of task is saved only when task is owned by node, task which was reclaimed by another worker is ignored */
+func (p psqlRepository) FinishTask(ctx context.Context, item *models.TaskQueue) error {
+	sql := `
+		UPDATE task_queues
+		SET status=?, result=?, parameter=?, exception=?, stacktrace=?, updated_at=?
+		WHERE id = ? AND node_id = ? AND status = ?
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx,
+		/* update */
+		item.Status,
+		item.Result,
+		item.Parameter,
+		item.TaskException,
+		item.StackTrace,
+		item.UpdatedAt,
+		/* where */
+		item.Id,
+		item.NodeId,
+		constants.QUEUE_STATUS_RUNNING,
+	)
+	return err
+}
+
+func (p psqlRepository) CancelTask(ctx context.Context, id int64) error {
+	sql := `
+		UPDATE task_queues
+		SET status=?, updated_at=NOW()
+		WHERE id = ? AND status IN (?, ?)
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, constants.QUEUE_STATUS_CANCELLED, id, constants.QUEUE_STATUS_QUEUED, constants.QUEUE_STATUS_RUNNING)
+	return err
+}
+
+func (p psqlRepository) GetTaskQueue(ctx context.Context, id int64) (*models.TaskQueue, error) {
+	var ptr = new(models.TaskQueue)
+	sql := `
+		SELECT 
+			*
+		FROM
+			task_queues
+		WHERE
+			id = ?
+	`
+
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, ptr, id); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ptr, nil
+}
+
+/* running task which heartbeat was older than timeout is queued again for another worker */
+func (p psqlRepository) RequeueStaleTasks(ctx context.Context, timeout time.Duration) (int64, error) {
+	sql := `
+		UPDATE task_queues
+		SET status=?, node_id=NULL, heartbeat_at=NULL, updated_at=NOW()
+		WHERE status = ? AND heartbeat_at < NOW() - make_interval(secs => ?)
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, constants.QUEUE_STATUS_QUEUED, constants.QUEUE_STATUS_RUNNING, timeout.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+/*
+acquire or renew lease which was held by node or was expired, return nil when lease is held by another node.
+fencing token is increased when lease was taken from another node
+*/
+func (p psqlRepository) AcquireLease(ctx context.Context, name string, nodeId string, ttl time.Duration) (*models.LeaderLease, error) {
+	var ptr = new(models.LeaderLease)
+	sql := `
+		INSERT INTO "leader_leases" ("name", "node_id", "fencing_token", "expired_at", "created_at", "updated_at")
+		VALUES (?, ?, 1, NOW() + make_interval(secs => ?), NOW(), NOW())
+		ON CONFLICT (name)
+		DO UPDATE SET
+			node_id=EXCLUDED.node_id,
+			fencing_token=CASE WHEN leader_leases.node_id = EXCLUDED.node_id THEN leader_leases.fencing_token ELSE leader_leases.fencing_token + 1 END,
+			expired_at=EXCLUDED.expired_at,
+			updated_at=NOW()
+		WHERE leader_leases.node_id = EXCLUDED.node_id OR leader_leases.expired_at < NOW()
+		RETURNING leader_leases.*
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, ptr, name, nodeId, ttl.Seconds()); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ptr, nil
+}
+
+func (p psqlRepository) GetLease(ctx context.Context, name string) (*models.LeaderLease, error) {
+	var ptr = new(models.LeaderLease)
+	sql := `
+		SELECT 
+			*
+		FROM
+			leader_leases
+		WHERE
+			name = ?
+	`
+
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, ptr, name); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ptr, nil
+}
+
+/* lease is valid when it is held by node with same fencing token and was not expired */
+func (p psqlRepository) CheckLease(ctx context.Context, name string, nodeId string, fencingToken int64) (bool, error) {
+	var isValid bool
+	sql := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM leader_leases
+			WHERE name = ? AND node_id = ? AND fencing_token = ? AND expired_at > NOW()
+		)
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.GetContext(ctx, &isValid, name, nodeId, fencingToken); err != nil {
+		return false, err
+	}
+	return isValid, nil
+}
+
+/* expire lease immediately, another node is able to acquire it on next renew */
+func (p psqlRepository) ReleaseLease(ctx context.Context, name string, nodeId string) error {
+	sql := `
+		UPDATE leader_leases
+		SET expired_at=NOW(), updated_at=NOW()
+		WHERE name = ? AND node_id = ?
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, name, nodeId)
+	return err
+}
+
+/* keep heartbeat of every running job which was run by node, waiting job is rerun which was claimed by node */
+func (p psqlRepository) HeartbeatJobs(ctx context.Context, nodeId string, jobIds []string) error {
+	if len(jobIds) == 0 {
+		return nil
+	}
+	var vals = []interface{}{nodeId, string(constants.JOB_STATUS_WAITING), string(constants.JOB_STATUS_RUNNING)}
+	var binds = make([]string, 0, len(jobIds))
+	for _, jobId := range jobIds {
+		binds = append(binds, "?")
+		vals = append(vals, jobId)
+	}
+	sql := fmt.Sprintf(`
+		UPDATE jobs
+		SET heartbeat_at=NOW()
+		WHERE node_id = ? AND status IN (?, ?) AND job_id IN (%s)
+	`, strings.Join(binds, ", "))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, vals...)
+	return err
+}
+
+/* claim running job which heartbeat was older than timeout, job which was claimed by another node is skipped */
+func (p psqlRepository) ClaimOrphanJobs(ctx context.Context, nodeId string, timeout time.Duration) ([]*models.Job, error) {
+	var ptrs = make([]*models.Job, 0)
+	sql := `
+		UPDATE jobs
+		SET node_id=?, heartbeat_at=NOW()
+		WHERE job_id IN (
+			SELECT job_id
+			FROM jobs
+			WHERE status IN (?, ?) AND COALESCE(heartbeat_at, updated_at) < NOW() - make_interval(secs => ?)
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING jobs.*
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SelectContext(ctx, &ptrs, nodeId, string(constants.JOB_STATUS_WAITING), string(constants.JOB_STATUS_RUNNING), timeout.Seconds()); err != nil {
+		return nil, err
+	}
+	return ptrs, nil
+}
+
+/* running job of node which was updated before node was started, it was left by previous process of node */
+func (p psqlRepository) ClaimNodeJobs(ctx context.Context, nodeId string, before time.Time) ([]*models.Job, error) {
+	var ptrs = make([]*models.Job, 0)
+	sql := `
+		UPDATE jobs
+		SET heartbeat_at=NOW()
+		WHERE node_id = ? AND status IN (?, ?) AND updated_at < ?
+		RETURNING jobs.*
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SelectContext(ctx, &ptrs, nodeId, string(constants.JOB_STATUS_WAITING), string(constants.JOB_STATUS_RUNNING), before); err != nil {
+		return nil, err
+	}
+	return ptrs, nil
+}
+
+/* job is claimed for rerun only when status was not changed since it was read, return false when another request claimed it before */
+func (p psqlRepository) ClaimRerunJob(ctx context.Context, jobId string, nodeId string, status constants.JobStatus) (bool, error) {
+	sql := `
+		UPDATE jobs
+		SET status=?, node_id=?, heartbeat_at=NOW(), updated_at=?
+		WHERE job_id = ? AND status = ?
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, string(constants.JOB_STATUS_WAITING), nodeId, time.Now(), jobId, string(status))
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+/* task of job which was not finished is failed with exception */
+func (p psqlRepository) FailRunningJobTasks(ctx context.Context, jobId string, exception string) error {
+	sql := `
+		UPDATE job_tasks
+		SET task_status=?, exception=?, end_datetime=?, updated_at=?
+		WHERE job_id = ? AND task_status IN (?, ?, ?, ?)
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	ti := time.Now()
+	_, err = stmt.ExecContext(ctx,
+		/* update */
+		string(constants.JOB_STATUS_FAILED),
+		exception,
+		ti,
+		ti,
+		/* where */
+		jobId,
+		string(constants.JOB_STATUS_WAITING),
+		string(constants.JOB_STATUS_RUNNING),
+		string(constants.JOB_STATUS_UP_FOR_RETRY),
+		string(constants.JOB_STATUS_UP_FOR_RESCHEDULE),
+	)
+	return err
+}
+
+/* cancel task of job on queue, worker which is running it is cancelled by heartbeat */
+func (p psqlRepository) CancelTasksByJobId(ctx context.Context, jobId string) error {
+	sql := `
+		UPDATE task_queues
+		SET status=?, updated_at=NOW()
+		WHERE job_id = ? AND status IN (?, ?)
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, constants.QUEUE_STATUS_CANCELLED, jobId, constants.QUEUE_STATUS_QUEUED, constants.QUEUE_STATUS_RUNNING)
+	return err
+}
+
+/* trigger which was triggered but job was not started, it is loaded again as timer of trigger */
+func (p psqlRepository) ReleaseTrigger(ctx context.Context, jobId string) error {
+	sql := `
+		UPDATE triggers
+		SET is_trigger=false, node_id='', claimed_at=NULL, updated_at=?
+		WHERE job_id = ? AND is_active = true
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, time.Now(), jobId)
+	return err
+}
+
+/* renew claim of trigger which job was not finished on node */
+func (p psqlRepository) HeartbeatTriggers(ctx context.Context, nodeId string, jobIds []string) error {
+	if len(jobIds) == 0 {
+		return nil
+	}
+	var vals = []interface{}{nodeId}
+	var binds = make([]string, 0, len(jobIds))
+	for _, jobId := range jobIds {
+		binds = append(binds, "?")
+		vals = append(vals, jobId)
+	}
+	sql := fmt.Sprintf(`
+		UPDATE triggers
+		SET claimed_at=NOW()
+		WHERE node_id = ? AND claimed_at IS NOT NULL AND job_id IN (%s)
+	`, strings.Join(binds, ", "))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, vals...)
+	return err
+}
+
+/* claim which was not renewed longer than timeout and job was never saved is released for any node claim again */
+func (p psqlRepository) ReleaseStaleTriggers(ctx context.Context, timeout time.Duration) (int64, error) {
+	sql := `
+		UPDATE triggers
+		SET is_trigger=false, node_id='', claimed_at=NULL, updated_at=?
+		WHERE is_trigger = true AND is_active = true AND claimed_at < NOW() - make_interval(secs => ?)
+		AND NOT EXISTS (SELECT 1 FROM jobs WHERE jobs.job_id = triggers.job_id)
+	`
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, time.Now(), timeout.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+/* running task of node is queued again for another worker */
+func (p psqlRepository) RequeueTasks(ctx context.Context, nodeId string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var vals = []interface{}{constants.QUEUE_STATUS_QUEUED, nodeId, constants.QUEUE_STATUS_RUNNING}
+	var binds = make([]string, 0, len(ids))
+	for _, id := range ids {
+		binds = append(binds, "?")
+		vals = append(vals, id)
+	}
+	sql := fmt.Sprintf(`
+		UPDATE task_queues
+		SET status=?, node_id=NULL, heartbeat_at=NULL, updated_at=NOW()
+		WHERE node_id = ? AND status = ? AND id IN (%s)
+	`, strings.Join(binds, ", "))
+	sql = sqlx.Rebind(sqlx.DOLLAR, sql)
+
+	stmt, err := p.client.PreparexContext(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, vals...)
+	return err
+}
@@ -12,6 +12,9 @@ import (
 type Validation struct {
 	triggerSchema            []byte
 	unActivatedTriggerSchema []byte
+	pauseSchedulerSchema     []byte
+	backfillSchema           []byte
+	rerunJobSchema           []byte
 }
 
 func NewValidation() Validation {
@@ -23,7 +26,19 @@ func NewValidation() Validation {
 	if err != nil {
 		panic(err)
 	}
-	return Validation{triggerSchema: bu, unActivatedTriggerSchema: bu2}
+	bu3, err := ioutil.ReadFile("./assets/jsonschema/v1/schedule/pause_scheduler_schema.json")
+	if err != nil {
+		panic(err)
+	}
+	bu4, err := ioutil.ReadFile("./assets/jsonschema/v1/schedule/backfill_schema.json")
+	if err != nil {
+		panic(err)
+	}
+	bu5, err := ioutil.ReadFile("./assets/jsonschema/v1/schedule/rerun_job_schema.json")
+	if err != nil {
+		panic(err)
+	}
+	return Validation{triggerSchema: bu, unActivatedTriggerSchema: bu2, pauseSchedulerSchema: bu3, backfillSchema: bu4, rerunJobSchema: bu5}
 }
 
 func (v Validation) getLoader(bu []byte) (*gojsonschema.Schema, error) {
@@ -98,3 +113,63 @@ func (v Validation) ValidateUnActivatedTrigger(next echo.HandlerFunc) echo.Handl
 		return next(c)
 	}
 }
+
+func (v Validation) ValidatePauseScheduler(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		schema, err := v.getLoader(v.pauseSchedulerSchema)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		var params = c.Get("params").(map[string]interface{})
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(params))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if !result.Valid() {
+			return echo.NewHTTPError(http.StatusBadRequest, v.toMap(result.Errors()))
+		}
+
+		return next(c)
+	}
+}
+
+func (v Validation) ValidateBackfill(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		schema, err := v.getLoader(v.backfillSchema)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		var params = c.Get("params").(map[string]interface{})
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(params))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if !result.Valid() {
+			return echo.NewHTTPError(http.StatusBadRequest, v.toMap(result.Errors()))
+		}
+
+		return next(c)
+	}
+}
+
+func (v Validation) ValidateRerunJob(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		schema, err := v.getLoader(v.rerunJobSchema)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		var params = c.Get("params").(map[string]interface{})
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(params))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if !result.Valid() {
+			return echo.NewHTTPError(http.StatusBadRequest, v.toMap(result.Errors()))
+		}
+
+		return next(c)
+	}
+}